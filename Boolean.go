@@ -0,0 +1,199 @@
+package Triangulate
+
+// BooleanOp selects the area that Boolean returns
+type BooleanOp int
+
+const (
+	Union        BooleanOp = iota // the area that is in a or in b
+	Intersection                  // the area that is in a and in b
+	Difference                    // the area of a that is not in b
+	Xor                           // the area that is in a or in b but not in both
+)
+
+// Boolean combines 2 sets of polygons with holes, for example clips a layer to a study area with Intersection.
+// The polygons of a set may overlap, a point is in a set when it is inside one of its polygons. The orientation of the
+// input rings is not used, the result has clockwise outer rings and counter-clockwise holes and can be triangulated.
+// The edges of both sets are split where they cross or touch and put in a constrained Delaunay triangulation, the
+// winding number of every triangle for both sets decides if the triangle is in the result, the rings of the result are
+// the edges between the triangles in and out. Points on a line between their neighbours are left out of the result,
+// rings of the result may touch each other in a point but do not touch themselves
+func Boolean(a, b []Polygon, op BooleanOp) ([]Polygon, error) {
+	var inside func(winding [2]int) bool
+	switch op {
+	case Intersection:
+		inside = func(w [2]int) bool { return w[0] > 0 && w[1] > 0 }
+	case Difference:
+		inside = func(w [2]int) bool { return w[0] > 0 && w[1] <= 0 }
+	case Xor:
+		inside = func(w [2]int) bool { return (w[0] > 0) != (w[1] > 0) }
+	default:
+		inside = func(w [2]int) bool { return w[0] > 0 || w[1] > 0 }
+	}
+	return overlay(append(polygonEdges(a, 0), polygonEdges(b, 1)...), inside)
+}
+
+// Dissolve merges polygons into the polygons of their union, the borders between neighbouring polygons disappear.
+// To dissolve regions by an attribute the polygons of every value are dissolved separately
+func Dissolve(polygons []Polygon) ([]Polygon, error) {
+	return overlay(polygonEdges(polygons, 0), func(w [2]int) bool { return w[0] > 0 })
+}
+
+// polygonEdges returns the edges of polygons as edges of set 0 or 1. The outer rings are counter-clockwise and the
+// holes clockwise, so the winding number is 1 inside a polygon
+func polygonEdges(polygons []Polygon, set int) (edges []repairEdge) {
+	for _, polygon := range polygons {
+		if polygon.Outer == nil {
+			continue
+		}
+		rings := [][]Point{orientedRing(polygon.Outer.P, false)}
+		for _, hole := range polygon.Holes {
+			rings = append(rings, orientedRing(hole.P, true))
+		}
+		for _, ring := range rings {
+			edges = append(edges, ringEdges(ring, set)...)
+		}
+	}
+	return
+}
+
+// ringEdges returns the edges of a ring in its own direction as edges of set 0 or 1
+func ringEdges(ring []Point, set int) (edges []repairEdge) {
+	points, _ := cleanRing(ring, set)
+	if len(points) < 3 {
+		return nil
+	}
+	for i, p := range points {
+		edges = append(edges, repairEdge{p, points[(i+1)%len(points)], set})
+	}
+	return
+}
+
+// overlay returns the area that inside selects by the winding numbers of 2 sets of edges, edge.ring is the set
+func overlay(edges []repairEdge, inside func(winding [2]int) bool) (polygons []Polygon, err error) {
+	edges, _ = splitAllEdges(edges)
+	if len(edges) == 0 {
+		return nil, nil
+	}
+	points := make([]Point, 0, 2*len(edges))
+	for _, e := range edges {
+		points = append(points, e.a, e.b)
+	}
+	mesh := newTriMesh(points)
+	for _, p := range points {
+		mesh.insert(p)
+	}
+	for _, e := range edges {
+		if e := mesh.insertConstraint(mesh.insert(e.a), mesh.insert(e.b)); e != nil && err == nil {
+			err = e
+		}
+	}
+	// the change of the winding numbers from the left to the right of the mesh edges from the lower to the higher point
+	crossing := make(map[[2]int][2]int)
+	for _, e := range edges {
+		for _, edge := range mesh.segmentEdges(mesh.insert(e.a), mesh.insert(e.b)) {
+			key, change := edge, -1
+			if edge[0] > edge[1] {
+				key, change = [2]int{edge[1], edge[0]}, 1
+			}
+			delta := crossing[key]
+			delta[e.ring] += change
+			crossing[key] = delta
+		}
+	}
+	var selected []int
+	for t, w := range mesh.windings(crossing) {
+		if inside(w) {
+			selected = append(selected, t)
+		}
+	}
+	rings := mesh.boundaries(selected)
+	for i, ring := range rings {
+		rings[i] = withoutCollinear(ring)
+	}
+	return assemblePolygons(rings), err
+}
+
+// windings returns the winding numbers of every triangle, 0 around the super triangle. Crossing the edge from point
+// a to point b, a < b, from its left to its right adds crossing[{a, b}]
+func (m *triMesh) windings(crossing map[[2]int][2]int) [][2]int {
+	winding := make([][2]int, len(m.tris))
+	seen := make([]bool, len(m.tris))
+	var stack []int
+	for t, tri := range m.tris {
+		if tri[0] < 3 || tri[1] < 3 || tri[2] < 3 {
+			seen[t] = true
+			stack = append(stack, t)
+		}
+	}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for i, u := range m.adj[t] {
+			if u < 0 || seen[u] {
+				continue
+			}
+			w := winding[t]
+			if m.fixed[t][i] {
+				a, b := m.tris[t][i], m.tris[t][(i+1)%3] // t is left of a -> b
+				if a < b {
+					delta := crossing[[2]int{a, b}]
+					w[0], w[1] = w[0]+delta[0], w[1]+delta[1]
+				} else {
+					delta := crossing[[2]int{b, a}]
+					w[0], w[1] = w[0]-delta[0], w[1]-delta[1]
+				}
+			}
+			winding[u], seen[u] = w, true
+			stack = append(stack, u)
+		}
+	}
+	return winding
+}
+
+// segmentEdges returns the mesh edges from point a to point b, more than 1 when the segment passes through points
+func (m *triMesh) segmentEdges(a, b int) (edges [][2]int) {
+	pa, pb := m.points[a], m.points[b]
+	for n := 0; a != b && n < len(m.points); n++ {
+		if t, _ := m.findEdge(a, b); t >= 0 {
+			return append(edges, [2]int{a, b})
+		}
+		if t, _ := m.findEdge(b, a); t >= 0 {
+			return append(edges, [2]int{a, b})
+		}
+		// the next point on the segment is the nearest neighbour of a on it towards b
+		at := (m.points[a].X-pa.X)*(pb.X-pa.X) + (m.points[a].Y-pa.Y)*(pb.Y-pa.Y)
+		next, distance := -1, 0.0
+		start := m.vt[a]
+		for t, k := start, 0; ; k = 0 {
+			for m.tris[t][k] != a {
+				k++
+			}
+			v := m.tris[t][(k+1)%3]
+			p := m.points[v]
+			if d := (p.X-pa.X)*(pb.X-pa.X) + (p.Y-pa.Y)*(pb.Y-pa.Y); d > at && cross(pa, pb, p) == 0 && (next < 0 || d < distance) {
+				next, distance = v, d
+			}
+			if t = m.adj[t][(k+2)%3]; t < 0 || t == start {
+				break
+			}
+		}
+		if next < 0 {
+			return
+		}
+		edges = append(edges, [2]int{a, next})
+		a = next
+	}
+	return
+}
+
+// withoutCollinear returns the points of a ring without the points on a line between their neighbours
+func withoutCollinear(ring []Point) []Point {
+	kept := make([]Point, 0, len(ring))
+	for i, p := range ring {
+		prev, next := ring[(i+len(ring)-1)%len(ring)], ring[(i+1)%len(ring)]
+		if cross(prev, p, next) != 0 || isSpike(prev, p, next) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
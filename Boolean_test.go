@@ -0,0 +1,131 @@
+package Triangulate
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// square returns a closed square ring with its lower left corner at x, y
+func square(x, y, size float64) *Poly {
+	return closedPoly([]Point{{X: x, Y: y}, {X: x, Y: y + size}, {X: x + size, Y: y + size}, {X: x + size, Y: y}})
+}
+
+// polygonsArea returns the area of the polygons without their holes
+func polygonsArea(polygons []Polygon) (area float64) {
+	for _, polygon := range polygons {
+		area += math.Abs(signedArea(openRing(polygon.Outer.P)))
+		for _, hole := range polygon.Holes {
+			area -= math.Abs(signedArea(openRing(hole.P)))
+		}
+	}
+	return
+}
+
+// inPolygons reports if p is inside one of the polygons and not in its holes
+func inPolygons(polygons []Polygon, p Point) bool {
+	for _, polygon := range polygons {
+		if !insideRing(p, openRing(polygon.Outer.P)) {
+			continue
+		}
+		in := true
+		for _, hole := range polygon.Holes {
+			if insideRing(p, openRing(hole.P)) {
+				in = false
+			}
+		}
+		if in {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBooleanArea(t *testing.T) {
+	withHole := []Polygon{{Outer: square(0, 0, 10), Holes: []*Poly{square(3, 3, 4)}}}
+	tests := []struct {
+		name string
+		a, b []Polygon
+		op   BooleanOp
+		want float64
+	}{
+		{"union", []Polygon{{Outer: square(0, 0, 2)}}, []Polygon{{Outer: square(1, 1, 2)}}, Union, 7},
+		{"intersection", []Polygon{{Outer: square(0, 0, 2)}}, []Polygon{{Outer: square(1, 1, 2)}}, Intersection, 1},
+		{"difference", []Polygon{{Outer: square(0, 0, 2)}}, []Polygon{{Outer: square(1, 1, 2)}}, Difference, 3},
+		{"xor", []Polygon{{Outer: square(0, 0, 2)}}, []Polygon{{Outer: square(1, 1, 2)}}, Xor, 6},
+		{"hole cut", withHole, []Polygon{{Outer: square(5, -1, 10)}}, Intersection, 45 - 8},
+		{"in hole", withHole, []Polygon{{Outer: square(4, 4, 2)}}, Union, 84 + 4},
+		{"disjoint", []Polygon{{Outer: square(0, 0, 1)}}, []Polygon{{Outer: square(5, 5, 1)}}, Intersection, 0},
+	}
+	for _, test := range tests {
+		result, err := Boolean(test.a, test.b, test.op)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := polygonsArea(result); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: area %g, want %g", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDissolve(t *testing.T) {
+	var cells []Polygon
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			cells = append(cells, Polygon{Outer: square(float64(i), float64(j), 1)})
+		}
+	}
+	result, err := Dissolve(cells)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || len(result[0].Holes) != 0 || len(openRing(result[0].Outer.P)) != 4 {
+		t.Fatalf("got %d polygons, want 1 square without holes", len(result))
+	}
+	if got := polygonsArea(result); got != 25 {
+		t.Errorf("area %g, want 25", got)
+	}
+}
+
+// TestBooleanSampled compares the result of random star polygons with the membership of random points in the input
+func TestBooleanSampled(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	star := func(cx, cy float64) Polygon {
+		n := 5 + r.Intn(20)
+		var ring []Point
+		for i := 0; i < n; i++ {
+			angle, radius := 2*math.Pi*float64(i)/float64(n), 1+3*r.Float64()
+			ring = append(ring, Point{X: cx + radius*math.Cos(angle), Y: cy + radius*math.Sin(angle)})
+		}
+		return Polygon{Outer: closedPoly(ring)}
+	}
+	for k := 0; k < 50; k++ {
+		a := []Polygon{star(0, 0), star(2, 1)}
+		b := []Polygon{star(1, -1)}
+		for _, op := range []BooleanOp{Union, Intersection, Difference, Xor} {
+			result, err := Boolean(a, b, op)
+			if err != nil {
+				t.Fatalf("%d %d: %v", k, op, err)
+			}
+			for _, polygon := range result {
+				if !polygon.Outer.IsClockwise() {
+					t.Errorf("%d %d: outer ring is counter-clockwise", k, op)
+				}
+				for _, hole := range polygon.Holes {
+					if hole.IsClockwise() {
+						t.Errorf("%d %d: hole is clockwise", k, op)
+					}
+				}
+			}
+			for s := 0; s < 200; s++ {
+				p := Point{X: -5 + 12*r.Float64(), Y: -6 + 12*r.Float64()}
+				inA, inB := inPolygons(a, p), inPolygons(b, p)
+				want := [...]bool{Union: inA || inB, Intersection: inA && inB, Difference: inA && !inB, Xor: inA != inB}[op]
+				if got := inPolygons(result, p); got != want {
+					t.Fatalf("%d %d: point %v in result %v, want %v", k, op, p, got, want)
+				}
+			}
+		}
+	}
+}
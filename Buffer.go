@@ -0,0 +1,198 @@
+package Triangulate
+
+import "math"
+
+// JoinStyle is the shape of a buffer around a corner
+type JoinStyle int
+
+const (
+	RoundJoin  JoinStyle = iota // an arc around the corner
+	MiterJoin                   // the offset edges are extended until they meet, up to the miter limit
+	SquareJoin                  // the corner is cut off at the buffer distance
+)
+
+// CapStyle is the shape of a buffer around the end of a line
+type CapStyle int
+
+const (
+	RoundCap  CapStyle = iota // a half circle around the end
+	SquareCap                 // the buffer is extended by the buffer distance beyond the end
+	ButtCap                   // the buffer ends at the end of the line
+)
+
+// BufferOptions of Buffer and BufferLines, the zero value gives round corners and ends
+type BufferOptions struct {
+	Join         JoinStyle
+	Cap          CapStyle
+	MiterLimit   float64 // largest distance of a miter point from its corner in buffer distances, 2 if 0, beyond it the corner is cut off like SquareJoin
+	ArcTolerance float64 // largest distance between a round join and the true arc in map units, a thousandth of the buffer distance if 0
+}
+
+// Buffer returns the area within distance of polygons with holes, a negative distance returns the area that is
+// further than -distance inside the polygons (an inset). The distance is in map units, lon/lat data is projected
+// first, see Projection. Every edge is moved by distance with joins around the corners, the moved rings are combined
+// with the polygons by the winding numbers of Boolean, so parts of the buffer that overlap and holes that close are
+// merged. The result has clockwise outer rings and counter-clockwise holes
+func Buffer(polygons []Polygon, distance float64, options BufferOptions) ([]Polygon, error) {
+	edges := polygonEdges(polygons, 0)
+	if distance == 0 {
+		return overlay(edges, func(w [2]int) bool { return w[0] > 0 })
+	}
+	for _, polygon := range polygons {
+		if polygon.Outer == nil {
+			continue
+		}
+		rings := [][]Point{orientedRing(polygon.Outer.P, false)}
+		for _, hole := range polygon.Holes {
+			rings = append(rings, orientedRing(hole.P, true))
+		}
+		for _, ring := range rings {
+			if ring, _ = cleanRing(ring, 0); len(ring) >= 3 {
+				edges = append(edges, ringEdges(offsetRing(ring, distance, options, false), 1)...)
+			}
+		}
+	}
+	if distance < 0 {
+		return overlay(edges, func(w [2]int) bool { return w[0] > 0 && w[1] > 0 })
+	}
+	return overlay(edges, func(w [2]int) bool { return w[0] > 0 || w[1] > 0 })
+}
+
+// BufferLines returns the area within distance of lines, for example the parts of a PolyLine shape, the buffers of
+// all lines are merged. A line of 1 point gives a circle with RoundCap, a square with SquareCap and nothing with
+// ButtCap. The distance is in map units and must be positive
+func BufferLines(lines [][]Point, distance float64, options BufferOptions) ([]Polygon, error) {
+	if distance <= 0 {
+		return nil, nil
+	}
+	var edges []repairEdge
+	for _, line := range lines {
+		var points []Point
+		for _, p := range line {
+			if len(points) == 0 || !equalXY(points[len(points)-1], p) {
+				p.UnDelete()
+				points = append(points, p)
+			}
+		}
+		switch {
+		case len(points) == 0:
+		case len(points) == 1:
+			edges = append(edges, ringEdges(pointBuffer(points[0], distance, options), 0)...)
+		default:
+			// the line and back again is a ring without area, its offset is the outline of the buffer
+			ring := append([]Point(nil), points...)
+			for i := len(points) - 2; i > 0; i-- {
+				ring = append(ring, points[i])
+			}
+			edges = append(edges, ringEdges(offsetRing(ring, distance, options, true), 0)...)
+		}
+	}
+	return overlay(edges, func(w [2]int) bool { return w[0] > 0 })
+}
+
+// offsetRing moves the edges of a ring without closing point to the right by distance, to the left for a negative
+// distance. Where the moved edges leave a gap around a corner the join closes it, where they overlap they are
+// connected through the corner, those loops are removed by the winding numbers. A ring of a line turns back at its
+// ends, there the cap is used
+func offsetRing(ring []Point, distance float64, options BufferOptions, line bool) (offset []Point) {
+	n, d := len(ring), math.Abs(distance)
+	step := arcStep(d, options)
+	limit := options.MiterLimit
+	if limit <= 0 {
+		limit = 2
+	}
+	// direction returns the unit vector from a to b
+	direction := func(a, b Point) (float64, float64) {
+		dx, dy := b.X-a.X, b.Y-a.Y
+		l := math.Hypot(dx, dy)
+		return dx / l, dy / l
+	}
+	at := func(v Point, x, y float64) Point {
+		v.X, v.Y = v.X+x, v.Y+y
+		return v
+	}
+	for i, v := range ring {
+		prev, next := ring[(i+n-1)%n], ring[(i+1)%n]
+		dx1, dy1 := direction(prev, v)
+		dx2, dy2 := direction(v, next)
+		x1, y1 := distance*dy1, -distance*dx1 // the normals to the right, times distance
+		x2, y2 := distance*dy2, -distance*dx2
+		turn := cross(prev, v, next)
+		back := turn == 0 && isSpike(prev, v, next) // the end of a line
+		if !back && float64(sign(turn))*distance <= 0 {
+			// the moved edges overlap, or meet when the corner is straight
+			offset = append(offset, at(v, x1, y1))
+			if turn != 0 {
+				offset = append(offset, v, at(v, x2, y2))
+			}
+			continue
+		}
+		// the moved edges turn by theta around the corner, in the direction of rotation
+		theta := math.Acos(math.Max(-1, math.Min(1, dx1*dx2+dy1*dy2)))
+		rotation := float64(sign(turn))
+		join := options.Join
+		if back {
+			theta, rotation = math.Pi, float64(sign(distance))
+			if line {
+				switch options.Cap {
+				case ButtCap:
+					offset = append(offset, at(v, x1, y1), at(v, x2, y2))
+					continue
+				case SquareCap:
+					join = SquareJoin
+				default:
+					join = RoundJoin
+				}
+			}
+		}
+		if join == MiterJoin {
+			// the miter point is on the bisector of the normals at distance d / cos(theta/2)
+			if c := math.Cos(theta / 2); c > 0 && 1/c <= limit {
+				offset = append(offset, at(v, (x1+x2)/(2*c*c), (y1+y2)/(2*c*c)))
+				continue
+			}
+			join = SquareJoin
+		}
+		if join == SquareJoin {
+			// the moved edges are extended by d tan(theta/4), the corner is cut off perpendicular to the bisector
+			t := d * math.Tan(theta/4)
+			offset = append(offset, at(v, x1+t*dx1, y1+t*dy1), at(v, x2-t*dx2, y2-t*dy2))
+			continue
+		}
+		segments := int(math.Ceil(theta / step))
+		start := math.Atan2(y1, x1)
+		for k := 0; k <= segments; k++ {
+			a := start + rotation*theta*float64(k)/float64(segments)
+			offset = append(offset, at(v, d*math.Cos(a), d*math.Sin(a)))
+		}
+	}
+	return
+}
+
+// pointBuffer returns the counter-clockwise circle or square around a point, nil for ButtCap
+func pointBuffer(p Point, distance float64, options BufferOptions) (ring []Point) {
+	switch options.Cap {
+	case ButtCap:
+		return nil
+	case SquareCap:
+		for _, c := range [][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+			ring = append(ring, Point{X: p.X + c[0]*distance, Y: p.Y + c[1]*distance, Z: p.Z, M: p.M})
+		}
+		return
+	}
+	segments := int(math.Ceil(2 * math.Pi / arcStep(distance, options)))
+	for k := 0; k < segments; k++ {
+		a := 2 * math.Pi * float64(k) / float64(segments)
+		ring = append(ring, Point{X: p.X + distance*math.Cos(a), Y: p.Y + distance*math.Sin(a), Z: p.Z, M: p.M})
+	}
+	return
+}
+
+// arcStep returns the largest angle of an arc segment of radius d that stays within the arc tolerance of the arc
+func arcStep(d float64, options BufferOptions) float64 {
+	tolerance := options.ArcTolerance
+	if tolerance <= 0 {
+		tolerance = d / 1000
+	}
+	return math.Max(2*math.Acos(1-math.Min(tolerance, d)/d), math.Pi/1000)
+}
@@ -0,0 +1,66 @@
+package Triangulate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBufferArea(t *testing.T) {
+	box := []Polygon{{Outer: square(0, 0, 10)}}
+	withHole := []Polygon{{Outer: square(0, 0, 10), Holes: []*Poly{square(4.5, 4.5, 1)}}}
+	tests := []struct {
+		name     string
+		polygons []Polygon
+		distance float64
+		options  BufferOptions
+		want     float64
+	}{
+		{"round", box, 1, BufferOptions{}, 140 + math.Pi},
+		{"miter", box, 1, BufferOptions{Join: MiterJoin}, 144},
+		{"square", box, 1, BufferOptions{Join: SquareJoin}, 140 + 4*(2*math.Sqrt2-2)},
+		{"inset round", box, -1, BufferOptions{}, 64},
+		{"inset miter", box, -1, BufferOptions{Join: MiterJoin}, 64},
+		{"zero", box, 0, BufferOptions{}, 100},
+		{"hole closes", withHole, 1, BufferOptions{Join: MiterJoin}, 144},
+		{"hole grows", withHole, -1, BufferOptions{Join: MiterJoin}, 64 - 9},
+		{"collapses", box, -6, BufferOptions{}, 0},
+	}
+	for _, test := range tests {
+		result, err := Buffer(test.polygons, test.distance, test.options)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := polygonsArea(result); math.Abs(got-test.want) > 0.01 {
+			t.Errorf("%s: area %.4f, want %.4f", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBufferLinesArea(t *testing.T) {
+	line := [][]Point{{{X: 0, Y: 0}, {X: 10, Y: 0}}}
+	point := [][]Point{{{X: 3, Y: 3}}}
+	tests := []struct {
+		name    string
+		lines   [][]Point
+		options BufferOptions
+		want    float64
+	}{
+		{"round", line, BufferOptions{}, 20 + math.Pi},
+		{"square", line, BufferOptions{Cap: SquareCap}, 24},
+		{"butt", line, BufferOptions{Cap: ButtCap}, 20},
+		{"point round", point, BufferOptions{}, math.Pi},
+		{"point square", point, BufferOptions{Cap: SquareCap}, 4},
+		{"point butt", point, BufferOptions{Cap: ButtCap}, 0},
+	}
+	for _, test := range tests {
+		result, err := BufferLines(test.lines, 1, test.options)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := polygonsArea(result); math.Abs(got-test.want) > 0.01 {
+			t.Errorf("%s: area %.4f, want %.4f", test.name, got, test.want)
+		}
+	}
+}
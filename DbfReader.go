@@ -0,0 +1,244 @@
+package shpReader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+The attributes of the shapes are stored in a dBASE III file (.dbf) with the same name as the .shp file,
+record n in the .dbf file belongs to record n in the .shp file.
+
+Field type 	Go value
+   C 	        string, trailing spaces removed
+   N 	        int64 without decimals, float64 with decimals
+   F 	        float64
+   D 	        time.Time
+   L 	        bool
+Empty (all spaces) N, F and D values and the unknown (?) logical are nil.
+N, F and D values that can not be parsed are kept as text without spaces and reported in DbfTable.Invalid.
+*/
+
+type DbfHeader struct {
+	/*
+	   Bytes Type   Usage
+	   0     byte   Version (0x03 dBASE III without memo)*/
+	Version byte
+	/* 1–3   byte   Date of last update YY (+1900) MM DD */
+	Year  byte
+	Month byte
+	Day   byte
+	/* 4–7   uint32 Number of records */
+	NumRecords uint32
+	/* 8–9   uint16 Length of the header including the field descriptors and terminator */
+	HeaderLength uint16
+	/* 10–11 uint16 Length of a record including the deletion flag */
+	RecordLength uint16
+	/* 12–31        Reserved, byte 29 holds the language driver (code page) */
+	_ [20]byte
+	/* Followed by field descriptors of 32 bytes each, terminated by 0x0D:
+	   Bytes Type   Usage
+	   0–10  char   Field name, null padded
+	   11    char   Field type (C, N, F, D, L)
+	   12–15        Reserved
+	   16    byte   Field length
+	   17    byte   Decimal count
+	   18–31        Reserved
+	*/
+}
+
+// Field describes a column of the attribute table
+type Field struct {
+	Name     string
+	Type     byte
+	Length   int
+	Decimals int
+}
+
+// Record holds the typed values of a single row, in the order of the fields
+type Record struct {
+	Deleted bool
+	Values  []interface{}
+}
+
+// DbfTable is the content of a .dbf file
+type DbfTable struct {
+	Header  DbfHeader
+	Fields  []Field
+	Records []Record
+	Invalid []error // values that could not be parsed, as RecordError
+}
+
+// Attributes holds the values of a record by field name
+type Attributes map[string]interface{}
+
+func (f Field) String() string {
+	return fmt.Sprintf("%s %c(%d,%d)", f.Name, f.Type, f.Length, f.Decimals)
+}
+
+// ReadDbf reads the field descriptors and all records of a .dbf file. A file that ends before the number of records
+// of the header returns the records that were read with a RecordError for ErrTruncated
+func ReadDbf(bf *BinFileReader) (table DbfTable, err error) {
+	buffer := bytes.NewBuffer(bf.ReadByte(32))
+	if err = binary.Read(buffer, binary.LittleEndian, &table.Header); err != nil {
+		return
+	}
+	if int(table.Header.HeaderLength) > bf.length {
+		return table, errors.New("Not a dBASE file")
+	}
+	recordLength := 1 // deletion flag
+	for bf.pos+32 < int(table.Header.HeaderLength) && bf.b[bf.pos] != 0x0D {
+		desc := bf.ReadByte(32)
+		field := Field{
+			Name:     strings.TrimRight(string(desc[:11]), "\x00 "),
+			Type:     desc[11],
+			Length:   int(desc[16]),
+			Decimals: int(desc[17]),
+		}
+		table.Fields = append(table.Fields, field)
+		recordLength += field.Length
+	}
+	if recordLength != int(table.Header.RecordLength) {
+		return table, fmt.Errorf("field lengths %d do not match record length %d", recordLength, table.Header.RecordLength)
+	}
+	bf.pos = int(table.Header.HeaderLength)
+	for i := 0; i < int(table.Header.NumRecords); i++ {
+		if bf.pos+recordLength > bf.length {
+			return table, &RecordError{Record: int32(i + 1), Offset: int64(bf.pos), Err: ErrTruncated}
+		}
+		start := bf.pos
+		content := bf.ReadByte(recordLength)
+		record := Record{Deleted: content[0] == '*', Values: make([]interface{}, len(table.Fields))}
+		offset := 1
+		for j, field := range table.Fields {
+			raw := content[offset : offset+field.Length]
+			var e error
+			if record.Values[j], e = parseValue(field, raw); e != nil {
+				record.Values[j] = strings.TrimSpace(string(raw))
+				table.Invalid = append(table.Invalid,
+					&RecordError{Record: int32(i + 1), Offset: int64(start + offset), Err: fmt.Errorf("field %s: %v", field.Name, e)})
+			}
+			offset += field.Length
+		}
+		table.Records = append(table.Records, record)
+	}
+	return
+}
+
+func parseValue(field Field, raw []byte) (value interface{}, err error) {
+	s := strings.TrimSpace(string(raw))
+	switch field.Type {
+	case 'C':
+		return strings.TrimRight(string(raw), " \x00"), nil
+	case 'N', 'F':
+		if s == "" || strings.Trim(s, "*") == "" { // blank or overflow
+			return nil, nil
+		}
+		if field.Type == 'N' && field.Decimals == 0 {
+			if i, e := strconv.ParseInt(s, 10, 64); e == nil {
+				return i, nil
+			}
+		}
+		return strconv.ParseFloat(s, 64)
+	case 'D':
+		if s == "" || strings.Trim(s, "0") == "" {
+			return nil, nil
+		}
+		return time.Parse("20060102", s)
+	case 'L':
+		switch s {
+		case "T", "t", "Y", "y":
+			return true, nil
+		case "F", "f", "N", "n":
+			return false, nil
+		}
+		return nil, nil
+	}
+	return s, nil // unsupported types are returned as text
+}
+
+// Attributes returns the values of record n (0-based) by field name
+func (t DbfTable) Attributes(n int) Attributes {
+	if n < 0 || n >= len(t.Records) {
+		return nil
+	}
+	attributes := make(Attributes, len(t.Fields))
+	for i, field := range t.Fields {
+		attributes[field.Name] = t.Records[n].Values[i]
+	}
+	return attributes
+}
+
+// JoinAttributes sets the Attributes of every shape from the table by record number
+func JoinAttributes(shapes []ShapeData, table DbfTable) error {
+	for i := range shapes {
+		n := int(shapes[i].RecordNum) - 1
+		if n < 0 || n >= len(table.Records) {
+			return fmt.Errorf("record %d: no attributes, table has %d records", shapes[i].RecordNum, len(table.Records))
+		}
+		shapes[i].Attributes = table.Attributes(n)
+	}
+	return nil
+}
+
+// String returns the value of the field as text, empty for unknown fields and nil values
+func (a Attributes) String(name string) string {
+	switch v := a[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format("2006-01-02")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Float returns the value of a numeric field
+func (a Attributes) Float(name string) (float64, bool) {
+	switch v := a[name].(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Int returns the value of a numeric field without decimals
+func (a Attributes) Int(name string) (int64, bool) {
+	v, ok := a[name].(int64)
+	return v, ok
+}
+
+// Bool returns the value of a logical field
+func (a Attributes) Bool(name string) (bool, bool) {
+	v, ok := a[name].(bool)
+	return v, ok
+}
+
+// Date returns the value of a date field
+func (a Attributes) Date(name string) (time.Time, bool) {
+	v, ok := a[name].(time.Time)
+	return v, ok
+}
+
+// SidecarName returns the name of the file next to filename with extension ext (e.g. ".dbf"),
+// the lower and upper case extension are tried, empty if neither exists
+func SidecarName(filename, ext string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for _, name := range []string{base + strings.ToLower(ext), base + strings.ToUpper(ext)} {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
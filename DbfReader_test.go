@@ -0,0 +1,127 @@
+package shpReader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// dbfBytes returns a .dbf file with the fields and the rows as text, every value is padded to the field length
+func dbfBytes(fields []Field, rows [][]string) []byte {
+	buf := new(bytes.Buffer)
+	header := DbfHeader{Version: 3, NumRecords: uint32(len(rows)), HeaderLength: uint16(32 + 32*len(fields) + 1), RecordLength: 1}
+	for _, field := range fields {
+		header.RecordLength += uint16(field.Length)
+	}
+	binary.Write(buf, binary.LittleEndian, header)
+	for _, field := range fields {
+		desc := make([]byte, 32)
+		copy(desc, field.Name)
+		desc[11], desc[16], desc[17] = field.Type, byte(field.Length), byte(field.Decimals)
+		buf.Write(desc)
+	}
+	buf.WriteByte(0x0D)
+	for _, row := range rows {
+		buf.WriteByte(' ')
+		for i, field := range fields {
+			value := []byte(row[i])
+			buf.Write(append(value, bytes.Repeat([]byte{' '}, field.Length-len(value))...))
+		}
+	}
+	buf.WriteByte(0x1A)
+	return buf.Bytes()
+}
+
+var dbfFields = []Field{
+	{Name: "NAME", Type: 'C', Length: 8},
+	{Name: "POP", Type: 'N', Length: 6},
+	{Name: "AREA", Type: 'N', Length: 8, Decimals: 2},
+	{Name: "FOUNDED", Type: 'D', Length: 8},
+	{Name: "CAPITAL", Type: 'L', Length: 1},
+}
+
+func TestReadDbf(t *testing.T) {
+	content := dbfBytes(dbfFields, [][]string{
+		{"Utrecht", "361966", "99.21", "11220101", "F"},
+		{"Zwolle", "", "  119.28", "", "?"},
+	})
+	bf := NewFromBytes(content)
+	table, err := ReadDbf(&bf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Records) != 2 || len(table.Invalid) != 0 {
+		t.Fatalf("%d records, %d invalid values", len(table.Records), len(table.Invalid))
+	}
+	a := table.Attributes(0)
+	if a.String("NAME") != "Utrecht" {
+		t.Errorf("NAME %q", a.String("NAME"))
+	}
+	if v, ok := a.Int("POP"); !ok || v != 361966 {
+		t.Errorf("POP %v", a["POP"])
+	}
+	if v, ok := a.Float("AREA"); !ok || v != 99.21 {
+		t.Errorf("AREA %v", a["AREA"])
+	}
+	if v, ok := a.Date("FOUNDED"); !ok || !v.Equal(time.Date(1122, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("FOUNDED %v", a["FOUNDED"])
+	}
+	if v, ok := a.Bool("CAPITAL"); !ok || v {
+		t.Errorf("CAPITAL %v", a["CAPITAL"])
+	}
+	b := table.Attributes(1)
+	for _, name := range []string{"POP", "FOUNDED", "CAPITAL"} {
+		if b[name] != nil {
+			t.Errorf("%s %v, want nil", name, b[name])
+		}
+	}
+	if v, _ := b.Float("AREA"); v != 119.28 {
+		t.Errorf("AREA %v", b["AREA"])
+	}
+}
+
+func TestReadDbfInvalidValues(t *testing.T) {
+	content := dbfBytes(dbfFields, [][]string{
+		{"Delft", "1,5", "12.5", "2023-1-1", "T"},
+		{"Leiden", "125", "23.27", "20230101", "T"},
+	})
+	bf := NewFromBytes(content)
+	table, err := ReadDbf(&bf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Records) != 2 {
+		t.Fatalf("%d records, want 2", len(table.Records))
+	}
+	if len(table.Invalid) != 2 {
+		t.Fatalf("invalid values %v, want POP and FOUNDED of record 1", table.Invalid)
+	}
+	var recordError *RecordError
+	if !errors.As(table.Invalid[0], &recordError) || recordError.Record != 1 {
+		t.Errorf("invalid value reported as %v", table.Invalid[0])
+	}
+	a := table.Attributes(0)
+	if a["POP"] != "1,5" || a["FOUNDED"] != "2023-1-1" {
+		t.Errorf("invalid values kept as %q and %q", a["POP"], a["FOUNDED"])
+	}
+	if v, _ := table.Attributes(1).Int("POP"); v != 125 {
+		t.Errorf("POP of record 2 %v", table.Attributes(1)["POP"])
+	}
+}
+
+func TestReadDbfTruncated(t *testing.T) {
+	content := dbfBytes(dbfFields, [][]string{
+		{"Gouda", "73000", "18.1", "", "F"},
+		{"Hoorn", "73000", "52.7", "", "F"},
+	})
+	bf := NewFromBytes(content[:len(content)-10])
+	table, err := ReadDbf(&bf)
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("error %v, want %v", err, ErrTruncated)
+	}
+	if len(table.Records) != 1 {
+		t.Errorf("%d records, want the 1 complete record", len(table.Records))
+	}
+}
@@ -0,0 +1,532 @@
+package Triangulate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Method selects the triangulation algorithm of TriangulatePolygon
+type Method int
+
+const (
+	EarClipping         Method = iota // fast, may create long sliver triangles
+	ConstrainedDelaunay               // Delaunay triangles that keep the edges of the rings, the smallest angles are maximized
+)
+
+// Options of TriangulatePolygon, the zero value uses ear clipping. A minimum angle or a maximum area refines a
+// constrained Delaunay triangulation whatever the method, see GetRefinedTriangles
+type Options struct {
+	Method   Method
+	MinAngle float64 // smallest angle of a triangle in degrees, 0 for any angle
+	MaxArea  float64 // largest area of a triangle in map units, 0 for any area
+	Epsilon  float64 // points of the rings whose neighbours are at an angle with a sine below Epsilon are left out, 0 keeps all
+}
+
+// TriangulatePolygon triangulates a polygon with holes with the method of options, every 3 points form a triangle.
+// Options.Epsilon only changes the rings, the triangulation itself always uses the exact predicates
+func TriangulatePolygon(polygon Polygon, options Options) ([]Point, error) {
+	if options.Epsilon > 0 {
+		polygon = withoutNearlyCollinear(polygon, options.Epsilon)
+	}
+	if options.MinAngle > 0 || options.MaxArea > 0 {
+		return GetRefinedTriangles(polygon, options.MinAngle, options.MaxArea)
+	}
+	switch options.Method {
+	case EarClipping:
+		return GetPolygonTriangles(polygon)
+	case ConstrainedDelaunay:
+		return GetDelaunayTriangles(polygon)
+	}
+	return nil, fmt.Errorf("unknown triangulation method %d", options.Method)
+}
+
+// GetDelaunayTriangles returns the constrained Delaunay triangulation of a polygon with holes, every 3 points form
+// a counter-clockwise triangle. All points of the rings are inserted in a Delaunay triangulation, the edges of the
+// rings are forced into it by flipping the edges that cross them, then the triangles outside the outer ring and
+// inside the holes are removed. Duplicate points are used once
+func GetDelaunayTriangles(polygon Polygon) (triangles []Point, err error) {
+	mesh, err := constrainedMesh(polygon)
+	if mesh == nil {
+		return nil, err
+	}
+	return mesh.interiorPoints(), err
+}
+
+// constrainedMesh returns the constrained Delaunay triangulation of the rings of a polygon,
+// nil when the outer ring has less than 3 points
+func constrainedMesh(polygon Polygon) (mesh *triMesh, err error) {
+	rings := [][]Point{orientedRing(polygon.Outer.P, true)}
+	for _, hole := range polygon.Holes {
+		rings = append(rings, orientedRing(hole.P, false))
+	}
+	var all []Point
+	for _, ring := range rings {
+		all = append(all, ring...)
+	}
+	if len(rings[0]) < 3 {
+		return nil, nil
+	}
+	mesh = newTriMesh(all)
+	indexes := make([][]int, len(rings))
+	for r, ring := range rings {
+		for _, p := range ring {
+			indexes[r] = append(indexes[r], mesh.insert(p))
+		}
+	}
+	for _, ring := range indexes {
+		for i := range ring {
+			if e := mesh.insertConstraint(ring[i], ring[(i+1)%len(ring)]); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return mesh, err
+}
+
+// interiorPoints returns the corners of the triangles inside the constrained edges, every 3 points form a triangle
+func (m *triMesh) interiorPoints() (triangles []Point) {
+	for _, t := range m.interior() {
+		tri := m.tris[t]
+		triangles = append(triangles, m.points[tri[0]], m.points[tri[1]], m.points[tri[2]])
+	}
+	return
+}
+
+// triMesh is a triangulation with the neighbours of every triangle, the first 3 points form a super triangle
+// that contains all other points
+type triMesh struct {
+	points []Point
+	tris   [][3]int           // point indexes, counter-clockwise
+	adj    [][3]int           // triangle across edge i, from tris[t][i] to tris[t][(i+1)%3], -1 outside the super triangle
+	fixed  [][3]bool          // constrained edges are never flipped
+	inside []bool             // triangles inside the constrained edges while the mesh is refined, nil otherwise
+	vt     []int              // a triangle of every point
+	index  map[[2]float64]int // points by coordinate, a duplicate point is inserted once
+	last   int                // start of the search for the next point
+}
+
+// newTriMesh returns a mesh with a super triangle that is large compared to the bounds of points
+func newTriMesh(points []Point) *triMesh {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY, maxX, maxY = math.Min(minX, p.X), math.Min(minY, p.Y), math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	size := math.Max(maxX-minX, maxY-minY)
+	if size == 0 || math.IsInf(size, 0) {
+		size = 1
+	}
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+	if len(points) == 0 {
+		cx, cy = 0, 0
+	}
+	m := &triMesh{index: make(map[[2]float64]int, len(points))}
+	m.points = []Point{{X: cx - 20*size, Y: cy - 10*size}, {X: cx + 20*size, Y: cy - 10*size}, {X: cx, Y: cy + 20*size}}
+	m.vt = []int{0, 0, 0}
+	m.setTri(m.newTri(), [3]int{0, 1, 2}, [3]int{-1, -1, -1}, [3]bool{})
+	return m
+}
+
+func (m *triMesh) newTri() int {
+	m.tris = append(m.tris, [3]int{})
+	m.adj = append(m.adj, [3]int{-1, -1, -1})
+	m.fixed = append(m.fixed, [3]bool{})
+	return len(m.tris) - 1
+}
+
+func (m *triMesh) setTri(t int, tri, adj [3]int, fixed [3]bool) {
+	m.tris[t], m.adj[t], m.fixed[t] = tri, adj, fixed
+	for _, v := range tri {
+		m.vt[v] = t
+	}
+}
+
+// replaceAdj replaces neighbour old of triangle t by new
+func (m *triMesh) replaceAdj(t, old, new int) {
+	if t < 0 {
+		return
+	}
+	for i := range m.adj[t] {
+		if m.adj[t][i] == old {
+			m.adj[t][i] = new
+		}
+	}
+}
+
+// edgeIndex returns the index of edge a -> b in triangle t, -1 if t does not have the edge
+func (m *triMesh) edgeIndex(t, a, b int) int {
+	for i := 0; i < 3; i++ {
+		if m.tris[t][i] == a && m.tris[t][(i+1)%3] == b {
+			return i
+		}
+	}
+	return -1
+}
+
+// findEdge returns the triangle with edge a -> b by turning around a, counter-clockwise and
+// for a point of the super triangle clockwise as well
+func (m *triMesh) findEdge(a, b int) (t, i int) {
+	start := m.vt[a]
+	for turn := 2; turn >= 0; turn -= 2 {
+		t = start
+		for n := 0; n < len(m.tris); n++ {
+			k := 0
+			for m.tris[t][k] != a {
+				k++
+			}
+			if m.tris[t][(k+1)%3] == b {
+				return t, k
+			}
+			if t = m.adj[t][(k+turn)%3]; t < 0 {
+				break
+			}
+			if t == start {
+				return -1, -1
+			}
+		}
+	}
+	return -1, -1
+}
+
+// locate returns a triangle that contains p inside or on an edge, walking from the last triangle towards p
+func (m *triMesh) locate(p Point) int {
+	t := m.last
+	for steps := 0; steps < len(m.tris); steps++ {
+		next := -1
+		for i := 0; i < 3; i++ {
+			if cross(m.points[m.tris[t][i]], m.points[m.tris[t][(i+1)%3]], p) < 0 {
+				next = m.adj[t][i]
+				break
+			}
+		}
+		if next < 0 {
+			return t
+		}
+		t = next
+	}
+	for t := range m.tris { // the walk went round in circles
+		tri := m.tris[t]
+		if cross(m.points[tri[0]], m.points[tri[1]], p) >= 0 && cross(m.points[tri[1]], m.points[tri[2]], p) >= 0 && cross(m.points[tri[2]], m.points[tri[0]], p) >= 0 {
+			return t
+		}
+	}
+	return m.last
+}
+
+// insert adds a point and restores the Delaunay property around it, the index of the point is returned
+func (m *triMesh) insert(p Point) int {
+	key := [2]float64{p.X, p.Y}
+	if v, ok := m.index[key]; ok {
+		return v
+	}
+	t := m.locate(p)
+	v := len(m.points)
+	m.points = append(m.points, p)
+	m.vt = append(m.vt, t)
+	m.index[key] = v
+	m.last = t
+	tri := m.tris[t]
+	for i := 0; i < 3; i++ {
+		if cross(m.points[tri[i]], m.points[tri[(i+1)%3]], p) == 0 {
+			m.splitEdge(t, i, v)
+			return v
+		}
+	}
+	m.splitTriangle(t, v)
+	return v
+}
+
+// splitTriangle connects point v inside triangle t to the 3 corners
+func (m *triMesh) splitTriangle(t, v int) {
+	a, b, c := m.tris[t][0], m.tris[t][1], m.tris[t][2]
+	nAB, nBC, nCA := m.adj[t][0], m.adj[t][1], m.adj[t][2]
+	fAB, fBC, fCA := m.fixed[t][0], m.fixed[t][1], m.fixed[t][2]
+	t1, t2 := m.newTri(), m.newTri()
+	m.setTri(t, [3]int{a, b, v}, [3]int{nAB, t1, t2}, [3]bool{fAB, false, false})
+	m.setTri(t1, [3]int{b, c, v}, [3]int{nBC, t2, t}, [3]bool{fBC, false, false})
+	m.setTri(t2, [3]int{c, a, v}, [3]int{nCA, t, t1}, [3]bool{fCA, false, false})
+	m.replaceAdj(nBC, t, t1)
+	m.replaceAdj(nCA, t, t2)
+	m.inherit(t, t1, t2)
+	m.legalize(v, t, t1, t2)
+}
+
+// splitEdge connects point v on edge i of triangle t to the opposite corners of the triangles on both sides,
+// the halves of a constrained edge stay constrained
+func (m *triMesh) splitEdge(t, i, v int) {
+	a, b, c := m.tris[t][i], m.tris[t][(i+1)%3], m.tris[t][(i+2)%3]
+	u, fAB := m.adj[t][i], m.fixed[t][i]
+	nBC, nCA := m.adj[t][(i+1)%3], m.adj[t][(i+2)%3]
+	fBC, fCA := m.fixed[t][(i+1)%3], m.fixed[t][(i+2)%3]
+	t1 := m.newTri()
+	if u < 0 {
+		m.setTri(t, [3]int{c, a, v}, [3]int{nCA, -1, t1}, [3]bool{fCA, fAB, false})
+		m.setTri(t1, [3]int{b, c, v}, [3]int{nBC, t, -1}, [3]bool{fBC, false, fAB})
+		m.replaceAdj(nBC, t, t1)
+		m.inherit(t, t1)
+		m.legalize(v, t, t1)
+		return
+	}
+	j := m.edgeIndex(u, b, a)
+	d := m.tris[u][(j+2)%3]
+	nAD, nDB := m.adj[u][(j+1)%3], m.adj[u][(j+2)%3]
+	fAD, fDB := m.fixed[u][(j+1)%3], m.fixed[u][(j+2)%3]
+	u1 := m.newTri()
+	m.setTri(t, [3]int{c, a, v}, [3]int{nCA, u, t1}, [3]bool{fCA, fAB, false})
+	m.setTri(t1, [3]int{b, c, v}, [3]int{nBC, t, u1}, [3]bool{fBC, false, fAB})
+	m.setTri(u, [3]int{a, d, v}, [3]int{nAD, u1, t}, [3]bool{fAD, false, fAB})
+	m.setTri(u1, [3]int{d, b, v}, [3]int{nDB, t1, u}, [3]bool{fDB, fAB, false})
+	m.replaceAdj(nBC, t, t1)
+	m.replaceAdj(nDB, u, u1)
+	m.inherit(t, t1)
+	m.inherit(u, u1)
+	m.legalize(v, t, t1, u, u1)
+}
+
+// meshEdge is the edge from a to b of triangle t, when t has changed the edge is skipped
+type meshEdge struct{ t, a, b int }
+
+// legalize flips the edges opposite the new point v until all triangles around v are Delaunay,
+// the edge opposite v is edge 0 of each of the triangles
+func (m *triMesh) legalize(v int, triangles ...int) {
+	var stack []meshEdge
+	for _, t := range triangles {
+		stack = append(stack, meshEdge{t, m.tris[t][0], m.tris[t][1]})
+	}
+	for n := 0; len(stack) > 0 && n < 10*len(m.tris); n++ {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i := m.edgeIndex(e.t, e.a, e.b)
+		if i < 0 || m.fixed[e.t][i] || m.adj[e.t][i] < 0 {
+			continue
+		}
+		u := m.adj[e.t][i]
+		d := m.tris[u][(m.edgeIndex(u, e.b, e.a)+2)%3]
+		if inCircle(m.points[e.a], m.points[e.b], m.points[v], m.points[d]) <= 0 {
+			continue
+		}
+		m.flip(e.t, i) // e.t = (a, d, v), u = (d, b, v)
+		stack = append(stack, meshEdge{e.t, e.a, d}, meshEdge{u, d, e.b})
+	}
+}
+
+// legalizeAll flips the edges between triangles of input points until all of them are Delaunay (Lawson),
+// constrained edges and triangles with a point of the super triangle are left as they are
+func (m *triMesh) legalizeAll() {
+	var stack []meshEdge
+	for t, tri := range m.tris {
+		for i := 0; i < 3; i++ {
+			stack = append(stack, meshEdge{t, tri[i], tri[(i+1)%3]})
+		}
+	}
+	for n := 0; len(stack) > 0 && n < 100*len(m.tris); n++ {
+		e := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		i := m.edgeIndex(e.t, e.a, e.b)
+		if i < 0 || m.fixed[e.t][i] || m.adj[e.t][i] < 0 {
+			continue
+		}
+		u := m.adj[e.t][i]
+		c := m.tris[e.t][(i+2)%3]
+		d := m.tris[u][(m.edgeIndex(u, e.b, e.a)+2)%3]
+		if e.a < 3 || e.b < 3 || c < 3 || d < 3 || inCircle(m.points[e.a], m.points[e.b], m.points[c], m.points[d]) <= 0 {
+			continue
+		}
+		m.flip(e.t, i) // e.t = (a, d, c), u = (d, b, c)
+		stack = append(stack, meshEdge{e.t, e.a, d}, meshEdge{e.t, c, e.a}, meshEdge{u, d, e.b}, meshEdge{u, e.b, c})
+	}
+}
+
+// flip replaces edge i of triangle t by the other diagonal of the quadrilateral of t and its neighbour.
+// Triangle t = (a, b, c) with neighbour u = (b, a, d) becomes t = (a, d, c) and u = (d, b, c)
+func (m *triMesh) flip(t, i int) {
+	a, b, c := m.tris[t][i], m.tris[t][(i+1)%3], m.tris[t][(i+2)%3]
+	u := m.adj[t][i]
+	j := m.edgeIndex(u, b, a)
+	d := m.tris[u][(j+2)%3]
+	nBC, nCA := m.adj[t][(i+1)%3], m.adj[t][(i+2)%3]
+	fBC, fCA := m.fixed[t][(i+1)%3], m.fixed[t][(i+2)%3]
+	nAD, nDB := m.adj[u][(j+1)%3], m.adj[u][(j+2)%3]
+	fAD, fDB := m.fixed[u][(j+1)%3], m.fixed[u][(j+2)%3]
+	m.setTri(t, [3]int{a, d, c}, [3]int{nAD, u, nCA}, [3]bool{fAD, false, fCA})
+	m.setTri(u, [3]int{d, b, c}, [3]int{nDB, nBC, t}, [3]bool{fDB, fBC, false})
+	m.replaceAdj(nAD, u, t)
+	m.replaceAdj(nBC, t, u)
+}
+
+// fix marks the edge between a and b as constrained on both sides
+func (m *triMesh) fix(a, b int) bool {
+	t, i := m.findEdge(a, b)
+	if t < 0 {
+		if t, i = m.findEdge(b, a); t < 0 {
+			return false
+		}
+	}
+	m.fixed[t][i] = true
+	if u := m.adj[t][i]; u >= 0 {
+		m.fixed[u][m.edgeIndex(u, m.tris[t][(i+1)%3], m.tris[t][i])] = true
+	}
+	return true
+}
+
+// insertConstraint forces the edge between points a and b into the mesh (S.W. Sloan, A fast algorithm for generating
+// constrained Delaunay triangulations). The edges crossing a-b are flipped until none is left, the new edges are
+// then flipped back where they are not Delaunay. An edge through a point is inserted as 2 edges
+func (m *triMesh) insertConstraint(a, b int) error {
+	if a == b || m.fix(a, b) {
+		return nil
+	}
+	crossing, on, err := m.crossingEdges(a, b)
+	if err != nil {
+		return err
+	}
+	if on >= 0 {
+		if err := m.insertConstraint(a, on); err != nil {
+			return err
+		}
+		return m.insertConstraint(on, b)
+	}
+	var created [][2]int
+	for n := 0; len(crossing) > 0; n++ {
+		if n > 10*len(m.tris) {
+			return fmt.Errorf("edge %v - %v could not be inserted", m.points[a], m.points[b])
+		}
+		e := crossing[0]
+		crossing = crossing[1:]
+		t, i := m.findEdge(e[0], e[1])
+		if t < 0 {
+			continue
+		}
+		if m.fixed[t][i] {
+			return fmt.Errorf("edge %v - %v crosses another edge", m.points[a], m.points[b])
+		}
+		u := m.adj[t][i]
+		c := m.tris[t][(i+2)%3]
+		d := m.tris[u][(m.edgeIndex(u, e[1], e[0])+2)%3]
+		if sign(cross(m.points[c], m.points[d], m.points[e[0]]))*sign(cross(m.points[c], m.points[d], m.points[e[1]])) >= 0 {
+			crossing = append(crossing, e) // the quadrilateral is not convex, try again after the other flips
+			continue
+		}
+		m.flip(t, i)
+		if segmentsCross(m.points[a], m.points[b], m.points[c], m.points[d]) {
+			crossing = append(crossing, [2]int{c, d})
+		} else {
+			created = append(created, [2]int{c, d})
+		}
+	}
+	m.fix(a, b)
+	for changed, n := true, 0; changed && n < len(created)+10; n++ {
+		changed = false
+		for k, e := range created {
+			t, i := m.findEdge(e[0], e[1])
+			if t < 0 || m.fixed[t][i] || m.adj[t][i] < 0 {
+				continue
+			}
+			u := m.adj[t][i]
+			c := m.tris[t][(i+2)%3]
+			d := m.tris[u][(m.edgeIndex(u, e[1], e[0])+2)%3]
+			if inCircle(m.points[e[0]], m.points[e[1]], m.points[c], m.points[d]) > 0 {
+				m.flip(t, i)
+				created[k] = [2]int{c, d}
+				changed = true
+			}
+		}
+	}
+	return nil
+}
+
+// crossingEdges returns the edges that cross the segment from a to b in the order they are crossed,
+// or the first point on the segment
+func (m *triMesh) crossingEdges(a, b int) (crossing [][2]int, on int, err error) {
+	pa, pb := m.points[a], m.points[b]
+	start := m.vt[a]
+	t, edge := start, -1
+	for n := 0; n < len(m.tris) && edge < 0; n++ { // the triangle around a through which the segment leaves a
+		k := 0
+		for m.tris[t][k] != a {
+			k++
+		}
+		v1, v2 := m.points[m.tris[t][(k+1)%3]], m.points[m.tris[t][(k+2)%3]]
+		o1, o2 := cross(pa, v1, pb), cross(pa, v2, pb)
+		if o1 == 0 && (v1.X-pa.X)*(pb.X-pa.X)+(v1.Y-pa.Y)*(pb.Y-pa.Y) > 0 {
+			return nil, m.tris[t][(k+1)%3], nil
+		}
+		if o1 > 0 && o2 < 0 {
+			edge = (k + 1) % 3
+			break
+		}
+		if t = m.adj[t][(k+2)%3]; t < 0 || t == start {
+			break
+		}
+	}
+	if edge < 0 {
+		return nil, -1, fmt.Errorf("edge %v - %v not found", pa, pb)
+	}
+	v1, v2 := m.tris[t][edge], m.tris[t][(edge+1)%3] // v1 is right of a -> b, v2 is left
+	for n := 0; n < len(m.tris); n++ {
+		crossing = append(crossing, [2]int{v1, v2})
+		u := m.adj[t][edge]
+		if u < 0 {
+			break
+		}
+		j := m.edgeIndex(u, v2, v1)
+		w := m.tris[u][(j+2)%3]
+		if w == b {
+			return crossing, -1, nil
+		}
+		switch o := cross(pa, pb, m.points[w]); {
+		case o == 0:
+			return nil, w, nil
+		case o < 0:
+			edge, v1 = (j+2)%3, w
+		default:
+			edge, v2 = (j+1)%3, w
+		}
+		t = u
+	}
+	return nil, -1, errors.New("edge leaves the triangulation")
+}
+
+// interior returns the triangles inside the constrained edges: a triangle is inside when an odd number of
+// constrained edges separates it from the super triangle
+func (m *triMesh) interior() (inside []int) {
+	depth := make([]int, len(m.tris))
+	var current, next []int
+	for t, tri := range m.tris {
+		depth[t] = -1
+		if tri[0] < 3 || tri[1] < 3 || tri[2] < 3 {
+			depth[t] = 0
+			current = append(current, t)
+		}
+	}
+	for level := 0; len(current) > 0; level++ {
+		for len(current) > 0 {
+			t := current[len(current)-1]
+			current = current[:len(current)-1]
+			if depth[t] != level { // reached without crossing a constrained edge
+				continue
+			}
+			if level%2 == 1 {
+				inside = append(inside, t)
+			}
+			for i, u := range m.adj[t] {
+				switch {
+				case u < 0 || (depth[u] >= 0 && depth[u] <= level):
+				case !m.fixed[t][i]:
+					depth[u] = level
+					current = append(current, u)
+				case depth[u] < 0:
+					depth[u] = level + 1
+					next = append(next, u)
+				}
+			}
+		}
+		current, next = next, nil
+	}
+	return
+}
+
+// segmentsCross reports if segments a-b and c-d cross in a point that is not an end point
+func segmentsCross(a, b, c, d Point) bool {
+	return sign(cross(a, b, c))*sign(cross(a, b, d)) < 0 && sign(cross(c, d, a))*sign(cross(c, d, b)) < 0
+}
@@ -0,0 +1,47 @@
+package Triangulate
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkConstrainedDelaunay checks that every edge between 2 counter-clockwise triangles that is not an edge of the
+// rings is locally Delaunay: the opposite point of one triangle is not inside the circle of the other
+func checkConstrainedDelaunay(t *testing.T, name string, polygon Polygon, triangles []Point) {
+	type edge [2]Point
+	constraints := make(map[edge]bool)
+	for _, ring := range append([]*Poly{polygon.Outer}, polygon.Holes...) {
+		points := openRing(ring.P)
+		for i, a := range points {
+			b := points[(i+1)%len(points)]
+			constraints[edge{a, b}], constraints[edge{b, a}] = true, true
+		}
+	}
+	opposite := make(map[edge]Point) // the third point of the triangle left of an edge
+	for i := 0; i+2 < len(triangles); i += 3 {
+		for j := 0; j < 3; j++ {
+			opposite[edge{triangles[i+j], triangles[i+(j+1)%3]}] = triangles[i+(j+2)%3]
+		}
+	}
+	for e, c := range opposite {
+		if d, ok := opposite[edge{e[1], e[0]}]; ok && !constraints[e] && InCircle(e[0], e[1], c, d) > 0 {
+			t.Fatalf("%s: %v is inside the circle of triangle %v %v %v", name, d, e[0], e[1], c)
+		}
+	}
+}
+
+func TestGetDelaunayTriangles(t *testing.T) {
+	check := func(name string, polygon Polygon) {
+		checkPolygonTriangles(t, name, polygon, GetDelaunayTriangles, 1)
+		triangles, _ := GetDelaunayTriangles(polygon)
+		checkConstrainedDelaunay(t, name, polygon, triangles)
+	}
+	check("square", Polygon{Outer: square(0, 0, 10)})
+	check("hole", Polygon{Outer: square(0, 0, 10), Holes: []*Poly{reversedSquare(3, 3, 4)}})
+	check("grid", holeGrid())
+	check("orientation", Polygon{Outer: reversedSquare(0, 0, 10), Holes: []*Poly{square(3, 3, 4)}})
+	r := rand.New(rand.NewSource(12))
+	for k := 0; k < 200; k++ {
+		check("star", starWithHoles(r))
+	}
+}
@@ -0,0 +1,259 @@
+package Triangulate
+
+import "math"
+
+// earClipper cuts ears off rings of linked vertices, a ring is split in 2 rings when it has no ear.
+// The rings are clockwise, every ear is added to ears as 3 points
+type earClipper struct {
+	p          []Point
+	prev, next []int
+	ears       []Point
+	forced     int // triangles cut off a ring that is not simple
+}
+
+// newEarClipper links the points of a clockwise ring without closing point, consecutive duplicates are skipped
+func newEarClipper(ring []Point) (c *earClipper, start, size int) {
+	c = &earClipper{}
+	for _, p := range ring {
+		if n := len(c.p); n > 0 && c.p[n-1].X == p.X && c.p[n-1].Y == p.Y {
+			continue
+		}
+		c.p = append(c.p, p)
+	}
+	for len(c.p) > 1 && c.p[0].X == c.p[len(c.p)-1].X && c.p[0].Y == c.p[len(c.p)-1].Y {
+		c.p = c.p[:len(c.p)-1]
+	}
+	size = len(c.p)
+	c.prev, c.next = make([]int, size), make([]int, size)
+	for i := range c.p {
+		c.prev[i], c.next[i] = (i+size-1)%size, (i+1)%size
+	}
+	return c, 0, size
+}
+
+func (c *earClipper) remove(i int) {
+	c.next[c.prev[i]] = c.next[i]
+	c.prev[c.next[i]] = c.prev[i]
+}
+
+func (c *earClipper) equal(i, j int) bool {
+	return c.p[i].X == c.p[j].X && c.p[i].Y == c.p[j].Y
+}
+
+// turn is negative for a convex (right) turn of a clockwise ring at b, 0 on a line
+func (c *earClipper) turn(a, b, d int) float64 {
+	return cross(c.p[a], c.p[b], c.p[d])
+}
+
+func (c *earClipper) emit(a, b, d int) {
+	c.ears = append(c.ears, c.p[a], c.p[b], c.p[d])
+}
+
+// clip triangulates the ring that contains start with size vertices. Ears are cut off as long as there are ears,
+// when a complete round finds none the fallbacks are tried in order: remove points on a line, spikes and duplicates,
+// cut off the triangle between 2 crossing edges, split the ring along a diagonal, and for rings that are not
+// simple cut off a convex corner anyway. Every step removes a point so clip always ends
+func (c *earClipper) clip(start, size int) {
+	i, stop, stage := start, start, 0
+	for size > 3 {
+		prev, next := c.prev[i], c.next[i]
+		if c.equal(i, next) || c.spike(prev, i, next) { // left over where the ring touches itself
+			c.remove(i)
+			size--
+			i, stop, stage = prev, prev, 0
+			continue
+		}
+		if c.isEar(i) {
+			c.emit(prev, i, next)
+			c.remove(i)
+			size--
+			i, stop, stage = next, next, 0
+			continue
+		}
+		if i = next; i != stop {
+			continue
+		}
+		switch stage {
+		case 0:
+			i, size = c.filter(i, size)
+		case 1:
+			i, size = c.cureIntersections(i, size)
+		case 2:
+			if a, b, ok := c.findDiagonal(i); ok {
+				a2, sizeA := c.split(a, b)
+				c.clip(a, sizeA)
+				c.clip(a2, size-sizeA+2)
+				return
+			}
+		default:
+			i = c.force(i)
+			size--
+		}
+		if stage < 3 {
+			stage++
+		}
+		stop = i
+	}
+	if size == 3 && c.turn(c.prev[i], i, c.next[i]) != 0 {
+		c.emit(c.prev[i], i, c.next[i])
+	}
+}
+
+// spike reports if the ring goes back at b along the edge it came from
+func (c *earClipper) spike(a, b, d int) bool {
+	return c.turn(a, b, d) == 0 && (c.p[a].X-c.p[b].X)*(c.p[d].X-c.p[b].X)+(c.p[a].Y-c.p[b].Y)*(c.p[d].Y-c.p[b].Y) > 0
+}
+
+// isEar reports if the triangle of i and its neighbours is inside the ring: the corner at i is convex and
+// no reflex point of the ring is inside or on the triangle. Points equal to a corner are skipped, they are
+// the other side of a bridge to a hole or a point where the ring touches itself
+func (c *earClipper) isEar(i int) bool {
+	a, b, d := c.prev[i], i, c.next[i]
+	if c.turn(a, b, d) >= 0 {
+		return false
+	}
+	for p := c.next[d]; p != a; p = c.next[p] {
+		if c.equal(p, a) || c.equal(p, b) || c.equal(p, d) || c.turn(c.prev[p], p, c.next[p]) < 0 {
+			continue
+		}
+		if inOrOnTriangle(c.p[a], c.p[b], c.p[d], c.p[p]) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter removes duplicate points, points on a line between their neighbours and spikes,
+// these can not be the corner of an ear
+func (c *earClipper) filter(start, size int) (int, int) {
+	for i, stop := start, start; size > 2; {
+		if c.equal(i, c.next[i]) || c.turn(c.prev[i], i, c.next[i]) == 0 {
+			c.remove(i)
+			size--
+			i, stop = c.prev[i], c.prev[i]
+			continue
+		}
+		if i = c.next[i]; i == stop {
+			return i, size
+		}
+	}
+	return c.next[start], size
+}
+
+// cureIntersections cuts off the triangle a, p, b where edge a-p crosses edge n-b, with n the point after p
+func (c *earClipper) cureIntersections(start, size int) (int, int) {
+	p := start
+	for n := 0; n < size && size > 3; n++ {
+		a, q := c.prev[p], c.next[p]
+		b := c.next[q]
+		if !c.equal(a, b) && segmentsCross(c.p[a], c.p[p], c.p[q], c.p[b]) && c.locallyInside(a, b) && c.locallyInside(b, a) {
+			c.emit(a, p, b)
+			c.remove(p)
+			c.remove(q)
+			size -= 2
+			p, start = b, b
+			continue
+		}
+		p = c.next[p]
+	}
+	return start, size
+}
+
+// findDiagonal returns 2 points of the ring that can be connected by a diagonal inside the ring
+func (c *earClipper) findDiagonal(start int) (a, b int, ok bool) {
+	a = start
+	for {
+		for b = c.next[c.next[a]]; b != c.prev[a]; b = c.next[b] {
+			if c.validDiagonal(a, b) {
+				return a, b, true
+			}
+		}
+		if a = c.next[a]; a == start {
+			return 0, 0, false
+		}
+	}
+}
+
+// validDiagonal reports if a-b does not cross the ring and runs inside the ring
+func (c *earClipper) validDiagonal(a, b int) bool {
+	if c.equal(a, b) || !c.locallyInside(a, b) || !c.locallyInside(b, a) {
+		return false
+	}
+	p := a
+	for {
+		q := c.next[p]
+		if !c.equal(p, a) && !c.equal(p, b) && !c.equal(q, a) && !c.equal(q, b) && segmentsTouch(c.p[p], c.p[q], c.p[a], c.p[b]) {
+			return false
+		}
+		if p = q; p == a {
+			break
+		}
+	}
+	// the middle of the diagonal is inside the ring
+	m := Point{X: (c.p[a].X + c.p[b].X) / 2, Y: (c.p[a].Y + c.p[b].Y) / 2}
+	inside := false
+	for p := a; ; {
+		q := c.next[p]
+		if crossesRay(c.p[p], c.p[q], m) {
+			inside = !inside
+		}
+		if p = q; p == a {
+			break
+		}
+	}
+	return inside
+}
+
+// locallyInside reports if the diagonal from a towards b starts inside the corner at a
+func (c *earClipper) locallyInside(a, b int) bool {
+	return insideCorner(c.p[c.prev[a]], c.p[a], c.p[c.next[a]], c.p[b])
+}
+
+// split connects a and b by a diagonal, a and b are duplicated so the ring falls apart in a ring
+// a, b, ... of sizeA points and a ring a2, ..., b2
+func (c *earClipper) split(a, b int) (a2, sizeA int) {
+	a2, b2 := len(c.p), len(c.p)+1
+	c.p = append(c.p, c.p[a], c.p[b])
+	c.prev = append(c.prev, 0, 0)
+	c.next = append(c.next, 0, 0)
+	an, bp := c.next[a], c.prev[b]
+	c.next[a], c.prev[b] = b, a
+	c.next[a2], c.prev[an] = an, a2
+	c.next[b2], c.prev[a2] = a2, b2
+	c.next[bp], c.prev[b2] = b2, bp
+	for p := c.next[a]; p != a; p = c.next[p] {
+		sizeA++
+	}
+	return a2, sizeA + 1
+}
+
+// force cuts off a convex corner of a ring that is not simple, the triangle may overlap other triangles
+func (c *earClipper) force(start int) int {
+	i := start
+	for c.turn(c.prev[i], i, c.next[i]) >= 0 {
+		if i = c.next[i]; i == start {
+			break
+		}
+	}
+	prev, next := c.prev[i], c.next[i]
+	if c.turn(prev, i, next) != 0 {
+		c.emit(prev, i, next)
+		c.forced++
+	}
+	c.remove(i)
+	return next
+}
+
+// segmentsTouch reports if segments a-b and c-d have a point in common
+func segmentsTouch(a, b, c, d Point) bool {
+	o1, o2, o3, o4 := cross(a, b, c), cross(a, b, d), cross(c, d, a), cross(c, d, b)
+	if ((o1 > 0 && o2 < 0) || (o1 < 0 && o2 > 0)) && ((o3 > 0 && o4 < 0) || (o3 < 0 && o4 > 0)) {
+		return true
+	}
+	return (o1 == 0 && onSegment(a, b, c)) || (o2 == 0 && onSegment(a, b, d)) || (o3 == 0 && onSegment(c, d, a)) || (o4 == 0 && onSegment(c, d, b))
+}
+
+// onSegment reports if p, on the line through a and b, is between a and b
+func onSegment(a, b, p Point) bool {
+	return p.X >= math.Min(a.X, b.X) && p.X <= math.Max(a.X, b.X) && p.Y >= math.Min(a.Y, b.Y) && p.Y <= math.Max(a.Y, b.Y)
+}
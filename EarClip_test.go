@@ -0,0 +1,87 @@
+package Triangulate
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// monotoneRing returns a clockwise x-monotone ring from the bytes: pairs of bytes are the heights of the upper and
+// lower chain at x = 0, 1, 2 ..., the chains meet nowhere so the ring is simple. Small heights give runs of collinear
+// points and vertical edges
+func monotoneRing(data []byte) []Point {
+	var upper, lower []Point
+	for i := 0; i+1 < len(data); i += 2 {
+		u, l := float64(data[i]%16), float64(data[i+1]%16)
+		if u == l {
+			u++
+		}
+		if u < l {
+			u, l = l, u
+		}
+		x := float64(i / 2)
+		upper = append(upper, Point{X: x, Y: u})
+		lower = append(lower, Point{X: x, Y: l})
+	}
+	ring := upper
+	for i := len(lower) - 1; i >= 0; i-- {
+		ring = append(ring, lower[i])
+	}
+	return ring
+}
+
+// checkTriangles checks that a simple ring is covered by clockwise triangles inside it, n-2 triangles when no corner
+// of the ring is on a line between its neighbours
+func checkTriangles(t *testing.T, ring []Point) {
+	poly := NewPoly()
+	for _, p := range ring {
+		poly.Add(p)
+	}
+	triangles, err := GetTrianglePoints(poly)
+	if err != nil {
+		t.Fatalf("ring %v: %v", ring, err)
+	}
+	n, collinear := len(ring), false
+	for i, p := range ring {
+		if cross(ring[(i+n-1)%n], p, ring[(i+1)%n]) == 0 {
+			collinear = true
+		}
+	}
+	if !collinear && len(triangles) != 3*(n-2) {
+		t.Fatalf("ring %v: %d triangles, want %d", ring, len(triangles)/3, n-2)
+	}
+	area := 0.0
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := triangles[i], triangles[i+1], triangles[i+2]
+		if cross(a, b, c) >= 0 {
+			t.Fatalf("ring %v: triangle %v %v %v is not clockwise", ring, a, b, c)
+		}
+		if centre := (Point{X: (a.X + b.X + c.X) / 3, Y: (a.Y + b.Y + c.Y) / 3}); !insideRing(centre, ring) {
+			t.Fatalf("ring %v: triangle %v %v %v is outside", ring, a, b, c)
+		}
+		area -= cross(a, b, c) / 2
+	}
+	if want := -signedArea(ring); area != want {
+		t.Fatalf("ring %v: triangles cover %v, want %v", ring, area, want)
+	}
+}
+
+func FuzzGetTrianglePoints(f *testing.F) {
+	f.Add([]byte{0, 5, 3, 1, 0, 5})
+	f.Add([]byte{9, 1, 2, 1, 9, 1, 2, 1, 9, 1})
+	f.Add([]byte{4, 0, 4, 0, 4, 0, 4, 0})
+	f.Add([]byte{15, 0, 1, 0, 15, 14, 1, 0, 15, 0, 7, 6})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if ring := monotoneRing(data); len(ring) >= 4 {
+			checkTriangles(t, ring)
+		}
+	})
+}
+
+func TestGetTrianglePointsRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	for k := 0; k < 2000; k++ {
+		data := make([]byte, 4+r.Intn(40))
+		r.Read(data)
+		checkTriangles(t, monotoneRing(data))
+	}
+}
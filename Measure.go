@@ -0,0 +1,352 @@
+package Triangulate
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Bounds is the bounding box of points
+type Bounds struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// Contains reports if p is inside the bounds or on their border
+func (b Bounds) Contains(p Point) bool {
+	return p.X >= b.MinX && p.X <= b.MaxX && p.Y >= b.MinY && p.Y <= b.MaxY
+}
+
+// SignedArea returns the area of the ring, positive when the points are counter-clockwise
+func (poly Poly) SignedArea() float64 {
+	return signedArea(openRing(poly.P))
+}
+
+// Area returns the area of the ring
+func (poly Poly) Area() float64 {
+	return math.Abs(poly.SignedArea())
+}
+
+// Perimeter returns the length of the ring including the edge from the last point back to the first
+func (poly Poly) Perimeter() float64 {
+	return ringLength(openRing(poly.P))
+}
+
+// Bounds returns the bounding box of the points, zero for a ring without points
+func (poly Poly) Bounds() Bounds {
+	return pointBounds(poly.P)
+}
+
+// Area returns the area of the outer ring minus the areas of the holes
+func (polygon Polygon) Area() float64 {
+	area, _ := polygon.moments()
+	return area
+}
+
+// Perimeter returns the length of the outer ring and the holes
+func (polygon Polygon) Perimeter() (length float64) {
+	for _, ring := range polygon.rings() {
+		length += ringLength(ring)
+	}
+	return
+}
+
+// Bounds returns the bounding box of the outer ring
+func (polygon Polygon) Bounds() Bounds {
+	if polygon.Outer == nil {
+		return Bounds{}
+	}
+	return polygon.Outer.Bounds()
+}
+
+// Centroid returns the centre of mass of the area between the outer ring and the holes. The centroid of a concave
+// polygon may be outside, use PoleOfInaccessibility to place a label
+func (polygon Polygon) Centroid() Point {
+	area, centroid := polygon.moments()
+	if area == 0 && polygon.Outer != nil {
+		return polygon.Outer.Centroid()
+	}
+	return centroid
+}
+
+// PoleOfInaccessibility returns the point inside the polygon that is furthest from the outer ring and the holes, and
+// its distance to them, the best place for a label (V. Agafonkin, polylabel). The bounding box is divided into square
+// cells, a cell is divided further while a point in it can be further away than the best point found, by more than
+// precision. A precision of 0 is a thousandth of the smaller side of the bounding box
+func (polygon Polygon) PoleOfInaccessibility(precision float64) (Point, float64) {
+	rings := polygon.rings()
+	if len(rings) == 0 || len(rings[0]) == 0 {
+		return ZP, 0
+	}
+	b := pointBounds(rings[0])
+	size := math.Min(b.MaxX-b.MinX, b.MaxY-b.MinY)
+	if size == 0 {
+		return Point{X: b.MinX, Y: b.MinY}, 0
+	}
+	if precision <= 0 {
+		precision = size / 1000
+	}
+	newCell := func(x, y, h float64) *cell {
+		d := ringsDistance(Point{X: x, Y: y}, rings)
+		return &cell{x: x, y: y, h: h, d: d, max: d + h*math.Sqrt2}
+	}
+	cells := &cellHeap{}
+	for x := b.MinX; x < b.MaxX; x += size {
+		for y := b.MinY; y < b.MaxY; y += size {
+			heap.Push(cells, newCell(x+size/2, y+size/2, size/2))
+		}
+	}
+	c := polygon.Centroid()
+	best := newCell(c.X, c.Y, 0)
+	if centre := newCell((b.MinX+b.MaxX)/2, (b.MinY+b.MaxY)/2, 0); centre.d > best.d {
+		best = centre
+	}
+	for cells.Len() > 0 {
+		c := heap.Pop(cells).(*cell)
+		if c.d > best.d {
+			best = c
+		}
+		if c.max-best.d <= precision {
+			continue
+		}
+		h := c.h / 2
+		heap.Push(cells, newCell(c.x-h, c.y-h, h))
+		heap.Push(cells, newCell(c.x+h, c.y-h, h))
+		heap.Push(cells, newCell(c.x-h, c.y+h, h))
+		heap.Push(cells, newCell(c.x+h, c.y+h, h))
+	}
+	return Point{X: best.x, Y: best.y}, best.d
+}
+
+// moments returns the area of the polygon and its centroid, the holes are subtracted whatever their orientation
+func (polygon Polygon) moments() (area float64, centroid Point) {
+	rings := polygon.rings()
+	if len(rings) == 0 {
+		return 0, ZP
+	}
+	origin := firstPoint(rings[0])
+	var sx, sy float64
+	for i, ring := range rings {
+		a, x, y := ringMoments(ring, origin)
+		if (a < 0) == (i == 0) { // the outer ring counts positive, the holes negative
+			a, x, y = -a, -x, -y
+		}
+		area, sx, sy = area+a, sx+x, sy+y
+	}
+	if area == 0 {
+		return 0, ZP
+	}
+	return area / 2, Point{X: origin.X + sx/(3*area), Y: origin.Y + sy/(3*area)}
+}
+
+// rings returns the outer ring and the holes without closing points
+func (polygon Polygon) rings() (rings [][]Point) {
+	if polygon.Outer == nil {
+		return nil
+	}
+	rings = append(rings, openRing(polygon.Outer.P))
+	for _, hole := range polygon.Holes {
+		rings = append(rings, openRing(hole.P))
+	}
+	return
+}
+
+// ringMoments returns twice the signed area of a ring and the sums of its first moments relative to origin,
+// the centroid is origin + (x, y) / (3 a). The points are moved to the origin to keep the precision of large coordinates
+func ringMoments(ring []Point, origin Point) (a, x, y float64) {
+	for i := range ring {
+		p, q := ring[i], ring[(i+1)%len(ring)]
+		px, py, qx, qy := p.X-origin.X, p.Y-origin.Y, q.X-origin.X, q.Y-origin.Y
+		c := px*qy - qx*py
+		a, x, y = a+c, x+(px+qx)*c, y+(py+qy)*c
+	}
+	return
+}
+
+// ringLength returns the length of a ring without closing point
+func ringLength(ring []Point) (length float64) {
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		length += math.Hypot(q.X-p.X, q.Y-p.Y)
+	}
+	return
+}
+
+// ringsDistance returns the distance of p to the nearest edge of the rings, negative when p is outside the polygon
+func ringsDistance(p Point, rings [][]Point) float64 {
+	inside, distance := false, math.Inf(1)
+	for _, ring := range rings {
+		if insideRing(p, ring) {
+			inside = !inside
+		}
+		for i, a := range ring {
+			distance = math.Min(distance, segmentDistance(p, a, ring[(i+1)%len(ring)]))
+		}
+	}
+	if !inside {
+		return -distance
+	}
+	return distance
+}
+
+func pointBounds(points []Point) Bounds {
+	if len(points) == 0 {
+		return Bounds{}
+	}
+	b := Bounds{MinX: points[0].X, MinY: points[0].Y, MaxX: points[0].X, MaxY: points[0].Y}
+	for _, p := range points[1:] {
+		b.MinX, b.MinY = math.Min(b.MinX, p.X), math.Min(b.MinY, p.Y)
+		b.MaxX, b.MaxY = math.Max(b.MaxX, p.X), math.Max(b.MaxY, p.Y)
+	}
+	return b
+}
+
+func firstPoint(points []Point) Point {
+	if len(points) == 0 {
+		return ZP
+	}
+	return points[0]
+}
+
+// cell is a square of the search of PoleOfInaccessibility with centre x, y and half size h, d is the distance of the
+// centre to the polygon and max the largest distance a point in the cell can have
+type cell struct {
+	x, y, h, d, max float64
+}
+
+// cellHeap returns the cell with the largest max first
+type cellHeap []*cell
+
+func (h cellHeap) Len() int            { return len(h) }
+func (h cellHeap) Less(i, j int) bool  { return h[i].max > h[j].max }
+func (h cellHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *cellHeap) Push(x interface{}) { *h = append(*h, x.(*cell)) }
+func (h *cellHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// WGS84 ellipsoid of the geodesic measurements
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+)
+
+// GeodesicArea returns the area of a ring of lon/lat points in degrees in square metres on the WGS84 ellipsoid.
+// The latitudes are converted to authalic latitudes, the area is the spherical excess of the ring on the sphere with
+// the area of the ellipsoid, the edges are great circles on that sphere. The ring may not contain a pole
+func (poly Poly) GeodesicArea() float64 {
+	return math.Abs(geodesicArea(openRing(poly.P)))
+}
+
+// GeodesicPerimeter returns the length of a ring of lon/lat points in degrees in metres on the WGS84 ellipsoid
+func (poly Poly) GeodesicPerimeter() float64 {
+	ring := openRing(poly.P)
+	if len(ring) < 2 {
+		return 0
+	}
+	return GeodesicLength(append(ring, ring[0]))
+}
+
+// GeodesicArea returns the area of a polygon of lon/lat points in degrees in square metres, the areas of the holes
+// are subtracted, see Poly.GeodesicArea
+func (polygon Polygon) GeodesicArea() float64 {
+	rings := polygon.rings()
+	if len(rings) == 0 {
+		return 0
+	}
+	area := math.Abs(geodesicArea(rings[0]))
+	for _, hole := range rings[1:] {
+		area -= math.Abs(geodesicArea(hole))
+	}
+	return area
+}
+
+// GeodesicPerimeter returns the length of the outer ring and the holes of a polygon of lon/lat points in metres
+func (polygon Polygon) GeodesicPerimeter() (length float64) {
+	if polygon.Outer == nil {
+		return 0
+	}
+	length = polygon.Outer.GeodesicPerimeter()
+	for _, hole := range polygon.Holes {
+		length += hole.GeodesicPerimeter()
+	}
+	return
+}
+
+// GeodesicLength returns the length of a line of lon/lat points in degrees in metres on the WGS84 ellipsoid
+func GeodesicLength(points []Point) (length float64) {
+	for i := 1; i < len(points); i++ {
+		length += GeodesicDistance(points[i-1], points[i])
+	}
+	return
+}
+
+// GeodesicDistance returns the length in metres of the shortest line between 2 lon/lat points in degrees on the
+// WGS84 ellipsoid (T. Vincenty, Direct and Inverse Solutions of Geodesics on the Ellipsoid). For nearly antipodal
+// points, where the iteration does not converge, the great circle distance on a sphere of the mean radius is used
+func GeodesicDistance(a, b Point) float64 {
+	const toRad = math.Pi / 180
+	f, semiMinor := wgs84F, wgs84A*(1-wgs84F)
+	L := (b.X - a.X) * toRad
+	U1, U2 := math.Atan((1-f)*math.Tan(a.Y*toRad)), math.Atan((1-f)*math.Tan(b.Y*toRad))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+	lambda := L
+	for i := 0; i < 200; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0 // the same point
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0 // on the equator
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		C := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		previous := lambda
+		lambda = L + (1-C)*f*sinAlpha*(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) > 1e-12 {
+			continue
+		}
+		u2 := cos2Alpha * (wgs84A*wgs84A - semiMinor*semiMinor) / (semiMinor * semiMinor)
+		A := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+		B := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+		deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return semiMinor * A * (sigma - deltaSigma)
+	}
+	// haversine
+	lat1, lat2 := a.Y*toRad, b.Y*toRad
+	h := math.Pow(math.Sin((lat2-lat1)/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(L/2), 2)
+	return 2 * (2*wgs84A + semiMinor) / 3 * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// geodesicArea returns the signed area of a ring of lon/lat points without closing point in square metres, positive
+// when the ring is counter-clockwise. Every edge adds the signed excess of the spherical triangle with the pole
+func geodesicArea(ring []Point) float64 {
+	const toRad = math.Pi / 180
+	e2 := wgs84F * (2 - wgs84F)
+	e := math.Sqrt(e2)
+	q := func(sinLat float64) float64 {
+		return (1 - e2) * (sinLat/(1-e2*sinLat*sinLat) - math.Log((1-e*sinLat)/(1+e*sinLat))/(2*e))
+	}
+	qp := q(1)
+	radius2 := wgs84A * wgs84A * qp / 2 // the square of the radius of the sphere with the area of the ellipsoid
+	// tan of half the authalic latitude of a point
+	tanHalf := func(p Point) float64 {
+		return math.Tan(math.Asin(math.Max(-1, math.Min(1, q(math.Sin(p.Y*toRad))/qp))) / 2)
+	}
+	excess := 0.0
+	for i, p := range ring {
+		n := ring[(i+1)%len(ring)]
+		dLon := math.Remainder((n.X-p.X)*toRad, 2*math.Pi)
+		t1, t2 := tanHalf(p), tanHalf(n)
+		excess += 2 * math.Atan2(math.Tan(dLon/2)*(t1+t2), 1+t1*t2)
+	}
+	return -excess * radius2
+}
@@ -0,0 +1,91 @@
+package Triangulate
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSignedAreaLargeCoordinates(t *testing.T) {
+	// a square of 1 cm on UTM coordinates, the products of the coordinates are 1e12 times its area
+	x, y := 500000.123, 4982950.456
+	ring := closedPoly([]Point{{X: x, Y: y}, {X: x, Y: y + 0.01}, {X: x + 0.01, Y: y + 0.01}, {X: x + 0.01, Y: y}})
+	if got := ring.SignedArea(); math.Abs(got+1e-4) > 1e-9 {
+		t.Errorf("SignedArea %g, want -1e-4", got)
+	}
+	if !ring.IsClockwise() {
+		t.Error("clockwise square is not clockwise")
+	}
+	if got := signedArea(openRing(ring.P)); math.Abs(got+1e-4) > 1e-9 {
+		t.Errorf("signedArea %g, want -1e-4", got)
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	// more points on the bottom edge do not move the centroid of the area
+	outer := closedPoly([]Point{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: 0},
+		{X: 8, Y: 0}, {X: 6, Y: 0}, {X: 4, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 0}})
+	if got := (Polygon{Outer: outer}).Centroid(); math.Abs(got.X-5) > 1e-12 || math.Abs(got.Y-5) > 1e-12 {
+		t.Errorf("centroid %v, want 5, 5", got)
+	}
+	withHole := Polygon{Outer: square(0, 0, 10), Holes: []*Poly{square(5, 0, 5)}}
+	if got := withHole.Area(); got != 75 {
+		t.Errorf("area %g, want 75", got)
+	}
+	// the centroid of the remaining L is the weighted centroid of a 5×10 and a 5×5 rectangle
+	want := Point{X: (50*2.5 + 25*7.5) / 75, Y: (50*5 + 25*7.5) / 75}
+	if got := withHole.Centroid(); math.Abs(got.X-want.X) > 1e-12 || math.Abs(got.Y-want.Y) > 1e-12 {
+		t.Errorf("centroid %v, want %v", got, want)
+	}
+}
+
+func TestPoleOfInaccessibility(t *testing.T) {
+	// a U of 10 × 10 with a gap of 6 × 8 from the top, the pole is in a bottom corner as far from the outer walls as
+	// from the inner corner: x = (2 - x) √2
+	u := Polygon{Outer: closedPoly([]Point{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 2, Y: 10}, {X: 2, Y: 2}, {X: 8, Y: 2},
+		{X: 8, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: 0}})}
+	p, d := u.PoleOfInaccessibility(0.001)
+	want := 2 * math.Sqrt2 / (1 + math.Sqrt2)
+	if d < want-0.001 || d > want+1e-12 || !insideRing(p, openRing(u.Outer.P)) {
+		t.Errorf("pole %v at %g, want a point %g from the border", p, d, want)
+	}
+	p, d = Polygon{Outer: square(0, 0, 10)}.PoleOfInaccessibility(0)
+	if math.Abs(p.X-5) > 0.01 || math.Abs(p.Y-5) > 0.01 || math.Abs(d-5) > 0.01 {
+		t.Errorf("pole %v at %g, want 5, 5 at 5", p, d)
+	}
+}
+
+func TestGeodesicDistance(t *testing.T) {
+	tests := []struct {
+		a, b Point
+		want float64
+	}{
+		{Point{X: 0, Y: 0}, Point{X: 1, Y: 0}, 111319.491},
+		{Point{X: 0, Y: 0}, Point{X: 0, Y: 1}, 110574.389},
+		{Point{X: 5, Y: 45}, Point{X: 5, Y: 45}, 0},
+	}
+	for _, test := range tests {
+		if got := GeodesicDistance(test.a, test.b); math.Abs(got-test.want) > 0.001 {
+			t.Errorf("%v - %v: %.4f m, want %.3f", test.a, test.b, got, test.want)
+		}
+	}
+	// nearly antipodal points fall back to the sphere
+	if got := GeodesicDistance(Point{X: 0, Y: 0}, Point{X: 180, Y: 0}); math.Abs(got-20003931.5) > 20003931.5*0.001 {
+		t.Errorf("antipodal distance %.1f m, want about 20003931.5", got)
+	}
+}
+
+func TestGeodesicArea(t *testing.T) {
+	cell := square(0, 0, 1)
+	if got := cell.GeodesicArea() / 1e6; math.Abs(got-12308.778) > 0.01 {
+		t.Errorf("area of a degree at the equator %.3f km², want 12308.778", got)
+	}
+	// the top edge is on latitude 1
+	want := 111319.491 + 2*110574.389 + GeodesicDistance(Point{X: 0, Y: 1}, Point{X: 1, Y: 1})
+	if got := cell.GeodesicPerimeter(); math.Abs(got-want) > 0.01 {
+		t.Errorf("perimeter %.3f m, want %.3f", got, want)
+	}
+	withHole := Polygon{Outer: square(0, 0, 2), Holes: []*Poly{square(0, 0, 1)}}
+	if got := withHole.GeodesicArea(); math.Abs(got-(square(0, 0, 2).GeodesicArea()-cell.GeodesicArea())) > 1e-3 {
+		t.Errorf("area with hole %.3f m²", got)
+	}
+}
@@ -0,0 +1,114 @@
+package Triangulate
+
+// Mesh is an indexed triangle mesh, every vertex is stored once and every 3 indices form a counter-clockwise triangle.
+// Indices are uint32 so the buffers can be passed to a renderer as they are
+type Mesh struct {
+	Vertices   []Point              // X and Y with the Z and M values of the input points
+	Indices    []uint32             // 3 per triangle
+	Attributes map[string][]float64 // optional values per vertex, see AddAttribute
+}
+
+// NewMesh returns the mesh of triangles given as 3 points each, like the result of TriangulatePolygon.
+// Points with the same X, Y, Z and M are the same vertex. Clockwise triangles are turned counter-clockwise,
+// triangles without area in the X,Y plane, like the walls of a MultiPatch, keep their order
+func NewMesh(triangles []Point) *Mesh {
+	m := &Mesh{Indices: make([]uint32, 0, len(triangles)-len(triangles)%3)}
+	index := make(map[Point]uint32, len(triangles)/2)
+	for t := 0; t+2 < len(triangles); t += 3 {
+		a, b, c := triangles[t], triangles[t+1], triangles[t+2]
+		if Orient2D(a, b, c) < 0 {
+			b, c = c, b
+		}
+		for _, p := range []Point{a, b, c} {
+			p.UnDelete()
+			i, ok := index[p]
+			if !ok {
+				i = uint32(len(m.Vertices))
+				index[p] = i
+				m.Vertices = append(m.Vertices, p)
+			}
+			m.Indices = append(m.Indices, i)
+		}
+	}
+	return m
+}
+
+// TriangulateMesh triangulates a polygon with holes with the method of options and returns the triangles as mesh
+func TriangulateMesh(polygon Polygon, options Options) (*Mesh, error) {
+	triangles, err := TriangulatePolygon(polygon, options)
+	return NewMesh(triangles), err
+}
+
+// Mesh returns the triangles of a Delaunay triangulation as mesh, the vertices are the points of the triangulation
+func (tr *Triangulation) Mesh() *Mesh {
+	m := &Mesh{Vertices: append([]Point(nil), tr.Points...), Indices: make([]uint32, 0, 3*len(tr.Triangles))}
+	for _, t := range tr.Triangles {
+		m.Indices = append(m.Indices, uint32(t[0]), uint32(t[1]), uint32(t[2]))
+	}
+	return m
+}
+
+// NumTriangles returns the number of triangles of the mesh
+func (m *Mesh) NumTriangles() int {
+	return len(m.Indices) / 3
+}
+
+// Triangle returns the corners of triangle t
+func (m *Mesh) Triangle(t int) (a, b, c Point) {
+	return m.Vertices[m.Indices[3*t]], m.Vertices[m.Indices[3*t+1]], m.Vertices[m.Indices[3*t+2]]
+}
+
+// TrianglePoints returns the corners of the triangles, every 3 points form a triangle
+func (m *Mesh) TrianglePoints() []Point {
+	points := make([]Point, len(m.Indices))
+	for i, v := range m.Indices {
+		points[i] = m.Vertices[v]
+	}
+	return points
+}
+
+// AddAttribute calculates a value for every vertex, for example a colour or a texture coordinate
+func (m *Mesh) AddAttribute(name string, value func(p Point) float64) {
+	if m.Attributes == nil {
+		m.Attributes = make(map[string][]float64)
+	}
+	values := make([]float64, len(m.Vertices))
+	for i, p := range m.Vertices {
+		values[i] = value(p)
+	}
+	m.Attributes[name] = values
+}
+
+// Adjacency returns for every triangle the triangle across edge i, from corner i to corner (i+1)%3,
+// -1 on the boundary of the mesh or when more than 2 triangles share the edge
+func (m *Mesh) Adjacency() [][3]int {
+	type edge struct{ from, to uint32 }
+	edges := make(map[edge]int, len(m.Indices))
+	for i, v := range m.Indices {
+		e := edge{v, m.Indices[i-i%3+(i+1)%3]}
+		if _, ok := edges[e]; ok {
+			edges[e] = -1 // the edge is used twice in the same direction
+			continue
+		}
+		edges[e] = i / 3
+	}
+	adjacency := make([][3]int, m.NumTriangles())
+	for i, v := range m.Indices {
+		adjacency[i/3][i%3] = -1
+		if t, ok := edges[edge{m.Indices[i-i%3+(i+1)%3], v}]; ok && edges[edge{v, m.Indices[i-i%3+(i+1)%3]}] >= 0 {
+			adjacency[i/3][i%3] = t
+		}
+	}
+	return adjacency
+}
+
+// VertexNeighbours returns the indexes of the vertices that share a triangle edge with every vertex
+func (m *Mesh) VertexNeighbours() [][]int {
+	neighbours := make([][]int, len(m.Vertices))
+	for i, v := range m.Indices {
+		a, b := int(v), int(m.Indices[i-i%3+(i+1)%3])
+		neighbours[a] = appendUnique(neighbours[a], b)
+		neighbours[b] = appendUnique(neighbours[b], a)
+	}
+	return neighbours
+}
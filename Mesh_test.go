@@ -0,0 +1,112 @@
+package Triangulate
+
+import "testing"
+
+// twoTriangleSquare returns a unit square as 2 clockwise triangles like ear clipping, the diagonal is shared
+func twoTriangleSquare() []Point {
+	return []Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 0}}
+}
+
+func TestNewMesh(t *testing.T) {
+	triangles := twoTriangleSquare()
+	triangles[3].Delete() // the deleted flag is not part of the vertex
+	m := NewMesh(triangles)
+	if len(m.Vertices) != 4 || m.NumTriangles() != 2 {
+		t.Fatalf("got %d vertices and %d triangles, want 4 and 2", len(m.Vertices), m.NumTriangles())
+	}
+	if m.Indices[0] != m.Indices[3] {
+		t.Errorf("indices %v: the corner 0, 0 of both triangles is not the same vertex", m.Indices)
+	}
+	for i := 0; i < m.NumTriangles(); i++ {
+		if a, b, c := m.Triangle(i); Orient2D(a, b, c) <= 0 {
+			t.Errorf("triangle %d %v %v %v is not counter-clockwise", i, a, b, c)
+		}
+	}
+	for i, p := range m.TrianglePoints() {
+		if !equalXY(p, triangles[i-i%3]) && !equalXY(p, triangles[i-i%3+1]) && !equalXY(p, triangles[i-i%3+2]) {
+			t.Errorf("corner %d %v is not a corner of the input triangle", i, p)
+		}
+	}
+
+	// the same X and Y with another Z is another vertex, a wall without area keeps its order
+	wall := []Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 0, Z: 5}}
+	m = NewMesh(wall)
+	if len(m.Vertices) != 3 || m.Indices[0] != 0 || m.Indices[1] != 1 || m.Indices[2] != 2 {
+		t.Errorf("wall: vertices %v, indices %v", m.Vertices, m.Indices)
+	}
+}
+
+func TestMeshAdjacency(t *testing.T) {
+	m := NewMesh(twoTriangleSquare())
+	adjacency := m.Adjacency()
+	shared := 0
+	for tri, across := range adjacency {
+		for i, u := range across {
+			if u < 0 {
+				continue
+			}
+			shared++
+			// the edge from corner i to i+1 is an edge of u in the other direction
+			from, to := m.Indices[3*tri+i], m.Indices[3*tri+(i+1)%3]
+			back := -1
+			for j := 0; j < 3; j++ {
+				if m.Indices[3*u+j] == to && m.Indices[3*u+(j+1)%3] == from {
+					back = j
+				}
+			}
+			if back < 0 || adjacency[u][back] != tri {
+				t.Errorf("triangle %d is across edge %d of triangle %d, but not the other way: %v", u, i, tri, adjacency)
+			}
+		}
+	}
+	if shared != 2 {
+		t.Errorf("adjacency %v: %d edges with a neighbour, want the diagonal twice", adjacency, shared)
+	}
+
+	// an edge of 3 triangles has no neighbour
+	fan := append(twoTriangleSquare(), Point{X: 0, Y: 0}, Point{X: 1, Y: 1}, Point{X: 2, Y: 0})
+	for tri, across := range NewMesh(fan).Adjacency() {
+		if across != [3]int{-1, -1, -1} {
+			t.Errorf("fan: triangle %d has neighbours %v", tri, across)
+		}
+	}
+}
+
+func TestVertexNeighbours(t *testing.T) {
+	m := NewMesh(twoTriangleSquare())
+	for v, neighbours := range m.VertexNeighbours() {
+		want := 2
+		if p := m.Vertices[v]; p.X == p.Y { // the ends of the diagonal
+			want = 3
+		}
+		if len(neighbours) != want {
+			t.Errorf("vertex %v: neighbours %v, want %d", m.Vertices[v], neighbours, want)
+		}
+		for _, n := range neighbours {
+			if n == v {
+				t.Errorf("vertex %v is its own neighbour", m.Vertices[v])
+			}
+		}
+	}
+}
+
+func TestTriangulateMesh(t *testing.T) {
+	polygon := Polygon{Outer: square(0, 0, 10), Holes: []*Poly{reversedSquare(3, 3, 4)}}
+	for _, method := range []Method{EarClipping, ConstrainedDelaunay} {
+		m, err := TriangulateMesh(polygon, Options{Method: method})
+		if err != nil {
+			t.Fatalf("method %d: %v", method, err)
+		}
+		if len(m.Vertices) != 8 || m.NumTriangles() != 8 {
+			t.Errorf("method %d: %d vertices and %d triangles, want 8 and 8", method, len(m.Vertices), m.NumTriangles())
+		}
+		area := 0.0
+		for i := 0; i < m.NumTriangles(); i++ {
+			a, b, c := m.Triangle(i)
+			area += cross(a, b, c) / 2
+		}
+		if area != 84 {
+			t.Errorf("method %d: area %v, want 84", method, area)
+		}
+	}
+}
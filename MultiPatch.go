@@ -0,0 +1,117 @@
+package Triangulate
+
+import (
+	"fmt"
+	"math"
+)
+
+// Part types of a MultiPatch shape, the values match the shapefile specification
+const (
+	TriangleStrip = 0 // every vertex after the first 2 forms a triangle with the 2 previous vertices
+	TriangleFan   = 1 // every vertex after the first 2 forms a triangle with the first and the previous vertex
+	OuterRing     = 2 // outer ring of a polygon, followed by its inner rings
+	InnerRing     = 3 // hole of the preceding outer ring
+	FirstRing     = 4 // first ring of a polygon of unspecified type, followed by rings
+	Ring          = 5 // ring of unspecified type
+)
+
+// StripTriangles returns the triangles of a triangle strip, every 3 points form a triangle.
+// The order of every other triangle is swapped so all triangles have the same orientation
+func StripTriangles(strip []Point) (triangles []Point) {
+	for i := 2; i < len(strip); i++ {
+		if i%2 == 0 {
+			triangles = append(triangles, strip[i-2], strip[i-1], strip[i])
+		} else {
+			triangles = append(triangles, strip[i-1], strip[i-2], strip[i])
+		}
+	}
+	return
+}
+
+// FanTriangles returns the triangles of a triangle fan, every 3 points form a triangle
+func FanTriangles(fan []Point) (triangles []Point) {
+	for i := 2; i < len(fan); i++ {
+		triangles = append(triangles, fan[0], fan[i-1], fan[i])
+	}
+	return
+}
+
+// GetPatchTriangles returns the triangles for all parts of a MultiPatch shape, every 3 points form a triangle.
+// parts holds the points of each part, partTypes the part type of each part.
+// Strips and fans are converted directly, rings are triangulated in the plane that fits them best so
+// vertical walls are triangulated as well. The inner rings following an outer ring and the rings following
+// a first ring are cut out of that ring.
+func GetPatchTriangles(parts [][]Point, partTypes []int32) (triangles []Point, err error) {
+	if len(parts) != len(partTypes) {
+		return nil, fmt.Errorf("%d parts with %d part types", len(parts), len(partTypes))
+	}
+	for i := 0; i < len(parts); i++ {
+		switch partTypes[i] {
+		case TriangleStrip:
+			triangles = append(triangles, StripTriangles(parts[i])...)
+		case TriangleFan:
+			triangles = append(triangles, FanTriangles(parts[i])...)
+		case OuterRing, FirstRing, Ring:
+			holeType := int32(InnerRing)
+			if partTypes[i] != OuterRing {
+				holeType = Ring
+			}
+			outer := parts[i]
+			var holes [][]Point
+			for partTypes[i] != Ring && i+1 < len(parts) && partTypes[i+1] == holeType {
+				i++
+				holes = append(holes, parts[i])
+			}
+			ears, e := ringTriangles(outer, holes)
+			if e != nil && err == nil {
+				err = e
+			}
+			triangles = append(triangles, ears...)
+		case InnerRing: // without outer ring
+		default:
+			return triangles, fmt.Errorf("part %d: unknown part type %d", i, partTypes[i])
+		}
+	}
+	return
+}
+
+// ringTriangles triangulates a ring with holes in 3D space by projecting it onto the axis plane
+// that is closest to the plane of the ring, the triangles hold the original points. The projected points carry the
+// index of their original point in Z, so points that project onto each other keep their own Z and M
+func ringTriangles(ring []Point, holes [][]Point) (ears []Point, err error) {
+	// Newell's method for the normal of the plane
+	var nx, ny, nz float64
+	for i := range ring {
+		p, q := ring[i], ring[(i+1)%len(ring)]
+		nx += (p.Y - q.Y) * (p.Z + q.Z)
+		ny += (p.Z - q.Z) * (p.X + q.X)
+		nz += (p.X - q.X) * (p.Y + q.Y)
+	}
+	project := func(p Point) Point { return Point{X: p.X, Y: p.Y} }
+	if math.Abs(nx) > math.Abs(nz) && math.Abs(nx) >= math.Abs(ny) {
+		project = func(p Point) Point { return Point{X: p.Y, Y: p.Z} }
+	} else if math.Abs(ny) > math.Abs(nz) {
+		project = func(p Point) Point { return Point{X: p.X, Y: p.Z} }
+	}
+	var original []Point
+	toPoly := func(ring []Point) *Poly {
+		poly := NewPoly()
+		for _, p := range ring {
+			pp := project(p)
+			pp.Z = float64(len(original))
+			original = append(original, p)
+			poly.Add(pp)
+		}
+		return poly
+	}
+	polygon := Polygon{Outer: toPoly(ring)}
+	for _, hole := range holes {
+		polygon.Holes = append(polygon.Holes, toPoly(hole))
+	}
+	projected, err := GetPolygonTriangles(polygon)
+	ears = make([]Point, len(projected))
+	for i, pp := range projected {
+		ears[i] = original[int(pp.Z)]
+	}
+	return ears, err
+}
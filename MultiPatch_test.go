@@ -0,0 +1,27 @@
+package Triangulate
+
+import "testing"
+
+func TestRingTrianglesKeepsTouchingPoints(t *testing.T) {
+	// the ring touches itself at (2, 0), once at height 0 and once at height 5
+	ring := []Point{
+		{X: 0, Y: 0}, {X: 0, Y: 4}, {X: 4, Y: 4}, {X: 4, Y: 0}, {X: 2, Y: 0},
+		{X: 3, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 0, Z: 5},
+	}
+	triangles, err := ringTriangles(ring, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triangles) != 3*(len(ring)-2) {
+		t.Fatalf("%d triangle points, want %d", len(triangles), 3*(len(ring)-2))
+	}
+	heights := make(map[float64]bool)
+	for _, p := range triangles {
+		if p.X == 2 && p.Y == 0 {
+			heights[p.Z] = true
+		}
+	}
+	if !heights[0] || !heights[5] {
+		t.Errorf("heights at the touching point %v, want 0 and 5", heights)
+	}
+}
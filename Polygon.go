@@ -0,0 +1,264 @@
+package Triangulate
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Polygon is an outer ring with the rings of its holes
+type Polygon struct {
+	Outer *Poly
+	Holes []*Poly
+}
+
+// RingsToPolygons groups the parts of a shape into polygons. Shapefiles store outer rings clockwise and
+// holes counter-clockwise, every hole belongs to the smallest outer ring that contains it.
+// A counter-clockwise ring that is not inside an outer ring is used as an outer ring
+func RingsToPolygons(rings []*Poly) (polygons []Polygon) {
+	var holes []*Poly
+	for _, ring := range rings {
+		if len(ring.P) < 3 { // simplified away
+			continue
+		}
+		if ring.IsClockwise() {
+			polygons = append(polygons, Polygon{Outer: ring})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+	for _, hole := range holes {
+		best, bestArea := -1, math.MaxFloat64
+		for i, polygon := range polygons {
+			if area := math.Abs(signedArea(polygon.Outer.P)); area < bestArea && containsRing(polygon.Outer.P, hole.P) {
+				best, bestArea = i, area
+			}
+		}
+		if best < 0 {
+			polygons = append(polygons, Polygon{Outer: hole})
+			continue
+		}
+		polygons[best].Holes = append(polygons[best].Holes, hole)
+	}
+	return
+}
+
+// GetPolygonTriangles triangulates a polygon and leaves its holes empty, every 3 points form a triangle.
+// Every hole is connected to the outer ring by a bridge, two edges in opposite directions, so the outer ring
+// and its holes form a single ring that is triangulated by GetTrianglePoints
+func GetPolygonTriangles(polygon Polygon) (ears []Point, err error) {
+	ring := orientedRing(polygon.Outer.P, true)
+	if len(ring) < 3 {
+		return nil, nil
+	}
+	var holes [][]Point
+	for _, hole := range polygon.Holes {
+		if h := orientedRing(hole.P, false); len(h) >= 3 {
+			holes = append(holes, h)
+		}
+	}
+	// the hole that reaches furthest to the right is bridged first, so a bridge never crosses a hole that is not yet bridged
+	sort.Slice(holes, func(i, j int) bool { return holes[i][rightMost(holes[i])].X > holes[j][rightMost(holes[j])].X })
+	for _, hole := range holes {
+		var e error
+		if ring, e = bridgeHole(ring, hole); e != nil && err == nil {
+			err = e
+		}
+	}
+	poly := NewPoly()
+	for _, p := range ring {
+		poly.Add(p)
+	}
+	if len(ring) > 0 {
+		poly.Add(ring[0]) // closed like a shapefile ring
+	}
+	ears, e := GetTrianglePoints(poly)
+	if err == nil {
+		err = e
+	}
+	return ears, err
+}
+
+// bridgeHole joins a counter-clockwise hole to a clockwise ring, see D. Eberly, Triangulation by Ear Clipping.
+// The rightmost point M of the hole is connected to a point P of the ring that is visible from M,
+// the result runs along the ring to P, around the hole from M back to M and from P along the rest of the ring
+func bridgeHole(ring, hole []Point) ([]Point, error) {
+	m := hole[rightMost(hole)]
+	// nearest intersection I of the ray from M to the right with an edge of the ring
+	edge, ix := -1, math.MaxFloat64
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		if a.Y == b.Y || (a.Y < m.Y && b.Y < m.Y) || (a.Y > m.Y && b.Y > m.Y) {
+			continue
+		}
+		if x := a.X + (m.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y); x >= m.X && x < ix {
+			edge, ix = i, x
+		}
+	}
+	if edge < 0 {
+		return ring, errors.New("hole outside outer ring")
+	}
+	a, b := ring[edge], ring[(edge+1)%len(ring)]
+	p := edge
+	switch {
+	case a.X == ix && a.Y == m.Y:
+	case b.X == ix && b.Y == m.Y:
+		p = (edge + 1) % len(ring)
+	default:
+		// P is the end of the edge furthest to the right, a reflex point inside triangle M, I, P would block
+		// the view from M to P, then the reflex point with the smallest angle to the ray is used, the nearest one
+		// when they are on a line with M
+		if b.X > a.X {
+			p = (edge + 1) % len(ring)
+		}
+		i := Point{X: ix, Y: m.Y}
+		candidate := ring[p]
+		// the triangle is on one side of the ray, a point has a smaller angle than the best point when it turns from
+		// the best point towards the ray
+		side := sign(candidate.Y - m.Y)
+		for j, v := range ring {
+			prev, next := ring[(j+len(ring)-1)%len(ring)], ring[(j+1)%len(ring)]
+			if j == p || cross(prev, v, next) <= 0 || !inOrOnTriangle(m, i, candidate, v) {
+				continue
+			}
+			best := ring[p]
+			if o := sign(cross(m, v, best)) * side; o > 0 || (o == 0 && (v.X < best.X || (v.X == best.X && side*sign(v.Y-best.Y) < 0))) {
+				p = j
+			}
+		}
+	}
+	// P may be in the ring more than once when it is the end of an earlier bridge, the bridge must start
+	// from the occurrence whose corner contains M
+	for j := range ring {
+		if ring[j].X == ring[p].X && ring[j].Y == ring[p].Y && insideCorner(ring[(j+len(ring)-1)%len(ring)], ring[j], ring[(j+1)%len(ring)], m) {
+			p = j
+			break
+		}
+	}
+	h := rightMost(hole)
+	merged := make([]Point, 0, len(ring)+len(hole)+2)
+	merged = append(merged, ring[:p+1]...)
+	merged = append(merged, hole[h:]...)
+	merged = append(merged, hole[:h+1]...)
+	merged = append(merged, ring[p:]...)
+	return merged, nil
+}
+
+// orientedRing returns a copy of the points without the closing point, clockwise or counter-clockwise
+func orientedRing(points []Point, clockwise bool) []Point {
+	ring := openRing(points)
+	if (signedArea(ring) < 0) != clockwise {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	return ring
+}
+
+// withoutNearlyCollinear returns the polygon with new rings without the points that are almost on a line with their
+// neighbours: the sine of the angle between the edges at the point is smaller than epsilon. A point stays when another
+// point of the polygon is inside its triangle with the neighbours, leaving it out would make the rings cross.
+// Rings keep at least 3 points
+func withoutNearlyCollinear(polygon Polygon, epsilon float64) Polygon {
+	if polygon.Outer == nil {
+		return polygon
+	}
+	rings := [][]Point{openRing(polygon.Outer.P)}
+	for _, hole := range polygon.Holes {
+		rings = append(rings, openRing(hole.P))
+	}
+	// blocks reports if a point of the polygon is strictly inside the triangle a, p, b
+	blocks := func(a, p, b Point) bool {
+		s := sign(cross(a, p, b))
+		for _, ring := range rings {
+			for _, q := range ring {
+				if !equalXY(q, a) && !equalXY(q, p) && !equalXY(q, b) &&
+					sign(cross(a, p, q)) == s && sign(cross(p, b, q)) == s && sign(cross(b, a, q)) == s {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for r, ring := range rings {
+		for removed := true; removed; {
+			removed = false
+			for i := 0; i < len(ring) && len(ring) > 3; i++ {
+				n := len(ring)
+				a, p, b := ring[(i+n-1)%n], ring[i], ring[(i+1)%n]
+				if math.Abs(cross(a, p, b)) <= epsilon*math.Hypot(a.X-p.X, a.Y-p.Y)*math.Hypot(b.X-p.X, b.Y-p.Y) && !blocks(a, p, b) {
+					ring = append(ring[:i], ring[i+1:]...)
+					rings[r], removed = ring, true
+					i--
+				}
+			}
+		}
+	}
+	result := Polygon{Outer: closedPoly(rings[0])}
+	for _, hole := range rings[1:] {
+		result.Holes = append(result.Holes, closedPoly(hole))
+	}
+	return result
+}
+
+// signedArea is positive for counter-clockwise points, the ring may be closed or open
+func signedArea(ring []Point) float64 {
+	a, _, _ := ringMoments(ring, firstPoint(ring))
+	return a / 2
+}
+
+// containsRing reports if most points of inner are inside outer, inner may touch outer
+func containsRing(outer, inner []Point) bool {
+	inside := 0
+	for _, p := range inner {
+		if insideRing(p, outer) {
+			inside++
+		}
+	}
+	return inside*2 > len(inner)
+}
+
+// insideRing is the even-odd test of a point against a ring
+func insideRing(p Point, ring []Point) (inside bool) {
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if crossesRay(ring[j], ring[i], p) {
+			inside = !inside
+		}
+	}
+	return
+}
+
+// crossesRay reports if edge a-b crosses the ray from p to the right, p is left of an upward edge
+// that it crosses and right of a downward edge
+func crossesRay(a, b, p Point) bool {
+	if (a.Y > p.Y) == (b.Y > p.Y) {
+		return false
+	}
+	if b.Y > a.Y {
+		return cross(a, b, p) > 0
+	}
+	return cross(a, b, p) < 0
+}
+
+// rightMost returns the index of the point with the largest X
+func rightMost(ring []Point) (m int) {
+	for i, p := range ring {
+		if p.X > ring[m].X {
+			m = i
+		}
+	}
+	return
+}
+
+// insideCorner reports if the direction from v to p is inside the corner prev, v, next of a clockwise ring
+func insideCorner(prev, v, next, p Point) bool {
+	if cross(prev, v, next) < 0 { // convex
+		return cross(v, next, p) < 0 && cross(v, prev, p) > 0
+	}
+	return cross(v, next, p) < 0 || cross(v, prev, p) > 0
+}
+
+func inOrOnTriangle(a, b, c, p Point) bool {
+	d1, d2, d3 := cross(a, b, p), cross(b, c, p), cross(c, a, p)
+	return !((d1 < 0 || d2 < 0 || d3 < 0) && (d1 > 0 || d2 > 0 || d3 > 0))
+}
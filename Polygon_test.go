@@ -0,0 +1,108 @@
+package Triangulate
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// reversedSquare returns a closed counter-clockwise square ring with its lower left corner at x, y
+func reversedSquare(x, y, size float64) *Poly {
+	return closedPoly(orientedRing(square(x, y, size).P, false))
+}
+
+func TestRingsToPolygons(t *testing.T) {
+	outer, lake := square(0, 0, 10), reversedSquare(3, 3, 4)
+	country, enclave := square(20, 0, 10), reversedSquare(22, 2, 6)
+	island, pond := square(24, 4, 2), reversedSquare(24.5, 4.5, 1)
+	lone := reversedSquare(50, 0, 5)
+	polygons := RingsToPolygons([]*Poly{lake, outer, pond, country, island, enclave, lone, closedPoly([]Point{{X: 1, Y: 1}})})
+	want := []struct {
+		outer *Poly
+		holes []*Poly
+	}{{outer, []*Poly{lake}}, {country, []*Poly{enclave}}, {island, []*Poly{pond}}, {lone, nil}}
+	if len(polygons) != len(want) {
+		t.Fatalf("got %d polygons, want %d", len(polygons), len(want))
+	}
+	for i, w := range want {
+		if polygons[i].Outer != w.outer {
+			t.Errorf("polygon %d: outer ring %v, want %v", i, polygons[i].Outer.P, w.outer.P)
+		}
+		if len(polygons[i].Holes) != len(w.holes) {
+			t.Errorf("polygon %d: %d holes, want %d", i, len(polygons[i].Holes), len(w.holes))
+			continue
+		}
+		for j, hole := range w.holes {
+			if polygons[i].Holes[j] != hole {
+				t.Errorf("polygon %d: hole %v, want %v", i, polygons[i].Holes[j].P, hole.P)
+			}
+		}
+	}
+}
+
+// checkPolygonTriangles triangulates a polygon and checks that the triangles have the orientation turn, are inside
+// the polygon and cover its area
+func checkPolygonTriangles(t *testing.T, name string, polygon Polygon, triangulate func(Polygon) ([]Point, error), turn int) {
+	triangles, err := triangulate(polygon)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	area := 0.0
+	for i := 0; i+2 < len(triangles); i += 3 {
+		a, b, c := triangles[i], triangles[i+1], triangles[i+2]
+		if sign(cross(a, b, c)) != turn {
+			t.Fatalf("%s: triangle %v %v %v has the wrong orientation", name, a, b, c)
+		}
+		if centre := (Point{X: (a.X + b.X + c.X) / 3, Y: (a.Y + b.Y + c.Y) / 3}); !inPolygons([]Polygon{polygon}, centre) {
+			t.Fatalf("%s: triangle %v %v %v is outside the polygon or in a hole", name, a, b, c)
+		}
+		area += math.Abs(cross(a, b, c)) / 2
+	}
+	if want := polygonsArea([]Polygon{polygon}); math.Abs(area-want) > 1e-9*want {
+		t.Fatalf("%s: triangles cover %v, want %v", name, area, want)
+	}
+}
+
+// holeGrid returns a square of size 10 with 9 holes, the holes of a column have the same rightmost X
+func holeGrid() Polygon {
+	grid := Polygon{Outer: square(0, 0, 10)}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			grid.Holes = append(grid.Holes, reversedSquare(float64(1+3*i), float64(1+3*j), 1))
+		}
+	}
+	return grid
+}
+
+// starWithHoles returns a star shaped outer ring with reflex corners and random square holes, the holes are in the
+// cells of a 5 by 5 grid inside the circle that the star contains
+func starWithHoles(r *rand.Rand) Polygon {
+	n := 5 + r.Intn(20)
+	var ring []Point
+	for i := n - 1; i >= 0; i-- {
+		angle, radius := 2*math.Pi*float64(i)/float64(n), 5+3*r.Float64()
+		ring = append(ring, Point{X: radius * math.Cos(angle), Y: radius * math.Sin(angle)})
+	}
+	polygon := Polygon{Outer: closedPoly(ring)}
+	for cell := 0; cell < 25; cell++ {
+		if r.Intn(3) == 0 {
+			size := 0.3 + 0.5*r.Float64()
+			x, y := float64(cell%5)-2.5+(1-size)*r.Float64(), float64(cell/5)-2.5+(1-size)*r.Float64()
+			polygon.Holes = append(polygon.Holes, reversedSquare(x, y, size))
+		}
+	}
+	return polygon
+}
+
+func TestGetPolygonTriangles(t *testing.T) {
+	check := func(name string, polygon Polygon) { checkPolygonTriangles(t, name, polygon, GetPolygonTriangles, -1) }
+	check("hole", Polygon{Outer: square(0, 0, 10), Holes: []*Poly{reversedSquare(3, 3, 4)}})
+	check("grid", holeGrid())
+	// the orientation of the rings is corrected
+	check("orientation", Polygon{Outer: reversedSquare(0, 0, 10), Holes: []*Poly{square(3, 3, 4)}})
+	// reflex corners of the outer ring block the view from a hole to the ring
+	r := rand.New(rand.NewSource(11))
+	for k := 0; k < 200; k++ {
+		check("star", starWithHoles(r))
+	}
+}
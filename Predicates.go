@@ -0,0 +1,161 @@
+package Triangulate
+
+import "math"
+
+// Error bounds of the floating point determinants, see J.R. Shewchuk, Adaptive Precision Floating-Point
+// Arithmetic and Fast Robust Geometric Predicates
+const (
+	roundoff    = 1.0 / (1 << 53) // half the distance between 1 and the next float64
+	orientBound = (3 + 16*roundoff) * roundoff
+	circleBound = (10 + 96*roundoff) * roundoff
+)
+
+// Orient2D is positive if a, b, c turn counter-clockwise, negative if they turn clockwise and 0 if they are on a line.
+// The sign is exact: the floating point determinant is used when it is far enough from 0,
+// otherwise the determinant is calculated exactly
+func Orient2D(a, b, c Point) float64 {
+	left, right := (a.X-c.X)*(b.Y-c.Y), (a.Y-c.Y)*(b.X-c.X)
+	det, permanent := left-right, math.Abs(left)+math.Abs(right)
+	if math.Abs(det) > orientBound*permanent {
+		return det
+	}
+	acx, acy := twoDiff(a.X, c.X), twoDiff(a.Y, c.Y)
+	bcx, bcy := twoDiff(b.X, c.X), twoDiff(b.Y, c.Y)
+	return mostSignificant(sumExpansions(mulExpansions(acx, bcy), negExpansion(mulExpansions(acy, bcx))))
+}
+
+// InCircle is positive if d is inside the circle through the counter-clockwise triangle a, b, c, negative if d is
+// outside and 0 if d is on the circle. The sign is exact like the sign of Orient2D
+func InCircle(a, b, c, d Point) float64 {
+	adx, ady := a.X-d.X, a.Y-d.Y
+	bdx, bdy := b.X-d.X, b.Y-d.Y
+	cdx, cdy := c.X-d.X, c.Y-d.Y
+	alift, blift, clift := adx*adx+ady*ady, bdx*bdx+bdy*bdy, cdx*cdx+cdy*cdy
+	det := alift*(bdx*cdy-cdx*bdy) + blift*(cdx*ady-adx*cdy) + clift*(adx*bdy-bdx*ady)
+	permanent := alift*(math.Abs(bdx*cdy)+math.Abs(cdx*bdy)) + blift*(math.Abs(cdx*ady)+math.Abs(adx*cdy)) +
+		clift*(math.Abs(adx*bdy)+math.Abs(bdx*ady))
+	if math.Abs(det) > circleBound*permanent {
+		return det
+	}
+	eadx, eady := twoDiff(a.X, d.X), twoDiff(a.Y, d.Y)
+	ebdx, ebdy := twoDiff(b.X, d.X), twoDiff(b.Y, d.Y)
+	ecdx, ecdy := twoDiff(c.X, d.X), twoDiff(c.Y, d.Y)
+	lift := func(x, y []float64) []float64 { return sumExpansions(mulExpansions(x, x), mulExpansions(y, y)) }
+	minor := func(x1, y1, x2, y2 []float64) []float64 {
+		return sumExpansions(mulExpansions(x1, y2), negExpansion(mulExpansions(x2, y1)))
+	}
+	exact := sumExpansions(mulExpansions(lift(eadx, eady), minor(ebdx, ebdy, ecdx, ecdy)),
+		sumExpansions(mulExpansions(lift(ebdx, ebdy), minor(ecdx, ecdy, eadx, eady)),
+			mulExpansions(lift(ecdx, ecdy), minor(eadx, eady, ebdx, ebdy))))
+	return mostSignificant(exact)
+}
+
+// cross is positive if a, b, c turn counter-clockwise
+func cross(a, b, c Point) float64 {
+	return Orient2D(a, b, c)
+}
+
+// inCircle is positive if d is inside the circle through the counter-clockwise triangle a, b, c
+func inCircle(a, b, c, d Point) float64 {
+	return InCircle(a, b, c, d)
+}
+
+// sign returns -1, 0 or 1, products of determinants are compared by sign because a product of two small exact
+// determinants can underflow to 0
+func sign(x float64) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
+
+// An expansion is a sum of float64 values that do not overlap, ordered by increasing magnitude,
+// it represents a sum or product of float64 values exactly
+
+// twoSum returns a + b as expansion
+func twoSum(a, b float64) (x, y float64) {
+	x = a + b
+	bv := x - a
+	av := x - bv
+	return x, (a - av) + (b - bv)
+}
+
+// twoDiff returns a - b as expansion
+func twoDiff(a, b float64) []float64 {
+	x, y := twoSum(a, -b)
+	return []float64{y, x}
+}
+
+// twoProduct returns a * b as sum x + y, the fused multiply-add calculates the rounding error y
+func twoProduct(a, b float64) (x, y float64) {
+	x = a * b
+	return x, math.FMA(a, b, -x)
+}
+
+// sumExpansions returns e + f, every component of f is added to e by a chain of exact sums
+func sumExpansions(e, f []float64) []float64 {
+	h := append(make([]float64, 0, len(e)+len(f)), e...)
+	for _, q := range f {
+		for i := range h {
+			q, h[i] = twoSum(q, h[i])
+		}
+		h = append(h, q)
+	}
+	return withoutZeros(h)
+}
+
+// scaleExpansion returns e * b
+func scaleExpansion(e []float64, b float64) []float64 {
+	if len(e) == 0 {
+		return nil
+	}
+	h := make([]float64, 0, 2*len(e))
+	q, low := twoProduct(e[0], b)
+	h = append(h, low)
+	for _, c := range e[1:] {
+		high, low := twoProduct(c, b)
+		var s float64
+		q, s = twoSum(q, low)
+		h = append(h, s)
+		q, s = twoSum(high, q)
+		h = append(h, s)
+	}
+	return withoutZeros(append(h, q))
+}
+
+// mulExpansions returns e * f
+func mulExpansions(e, f []float64) (product []float64) {
+	for _, b := range f {
+		product = sumExpansions(product, scaleExpansion(e, b))
+	}
+	return
+}
+
+func negExpansion(e []float64) []float64 {
+	n := make([]float64, len(e))
+	for i, c := range e {
+		n[i] = -c
+	}
+	return n
+}
+
+func withoutZeros(e []float64) []float64 {
+	n := e[:0]
+	for _, c := range e {
+		if c != 0 {
+			n = append(n, c)
+		}
+	}
+	return n
+}
+
+// mostSignificant returns the largest component of an expansion, it has the sign of the expansion
+func mostSignificant(e []float64) float64 {
+	if len(e) == 0 {
+		return 0
+	}
+	return e[len(e)-1]
+}
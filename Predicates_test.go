@@ -0,0 +1,136 @@
+package Triangulate
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func rat(x float64) *big.Rat {
+	return new(big.Rat).SetFloat64(x)
+}
+
+func ratSub(a, b float64) *big.Rat {
+	return new(big.Rat).Sub(rat(a), rat(b))
+}
+
+func ratMul(a, b *big.Rat) *big.Rat {
+	return new(big.Rat).Mul(a, b)
+}
+
+// exactOrient is the sign of the orientation determinant calculated with rationals
+func exactOrient(a, b, c Point) int {
+	left := ratMul(ratSub(a.X, c.X), ratSub(b.Y, c.Y))
+	right := ratMul(ratSub(a.Y, c.Y), ratSub(b.X, c.X))
+	return left.Cmp(right)
+}
+
+// exactInCircle is the sign of the in-circle determinant calculated with rationals
+func exactInCircle(a, b, c, d Point) int {
+	adx, ady := ratSub(a.X, d.X), ratSub(a.Y, d.Y)
+	bdx, bdy := ratSub(b.X, d.X), ratSub(b.Y, d.Y)
+	cdx, cdy := ratSub(c.X, d.X), ratSub(c.Y, d.Y)
+	lift := func(x, y *big.Rat) *big.Rat { return new(big.Rat).Add(ratMul(x, x), ratMul(y, y)) }
+	minor := func(x1, y1, x2, y2 *big.Rat) *big.Rat { return new(big.Rat).Sub(ratMul(x1, y2), ratMul(x2, y1)) }
+	det := ratMul(lift(adx, ady), minor(bdx, bdy, cdx, cdy))
+	det.Add(det, ratMul(lift(bdx, bdy), minor(cdx, cdy, adx, ady)))
+	det.Add(det, ratMul(lift(cdx, cdy), minor(adx, ady, bdx, bdy)))
+	return det.Sign()
+}
+
+func TestOrient2D(t *testing.T) {
+	for _, test := range []struct {
+		a, b, c Point
+	}{
+		{Point{X: 0, Y: 0}, Point{X: 1, Y: 1}, Point{X: 2, Y: 2}},
+		{Point{X: 0.1, Y: 0.1}, Point{X: 0.2, Y: 0.2}, Point{X: 0.3, Y: 0.3}},
+		{Point{X: 0.5, Y: 0.5}, Point{X: 12, Y: 12}, Point{X: 24, Y: 24}},
+		{Point{X: 0.5, Y: math.Nextafter(0.5, 1)}, Point{X: 12, Y: 12}, Point{X: 24, Y: 24}},
+		{Point{X: 1e15, Y: 1e15}, Point{X: 1e15 + 1, Y: 1e15 + 1}, Point{X: 1e15 + 2, Y: 1e15 + 2.0000001}},
+		{Point{X: 500000.1, Y: 5200000.3}, Point{X: 500000.2, Y: 5200000.6}, Point{X: 500000.3, Y: 5200000.9}},
+		{Point{X: 1e-300, Y: 1e-300}, Point{X: 2e-300, Y: 2e-300}, Point{X: 3e-300, Y: 3.0000000000000001e-300}},
+		{Point{X: 1, Y: 0}, Point{X: 0, Y: 1}, Point{X: 0, Y: 0}},
+	} {
+		if got, want := sign(Orient2D(test.a, test.b, test.c)), exactOrient(test.a, test.b, test.c); got != want {
+			t.Errorf("Orient2D(%v, %v, %v) has sign %d, want %d", test.a, test.b, test.c, got, want)
+		}
+	}
+}
+
+// TestOrient2DNearLine moves a point by units in the last place around a line, the grid of Shewchuk's paper
+func TestOrient2DNearLine(t *testing.T) {
+	b, c := Point{X: 12, Y: 12}, Point{X: 24, Y: 24}
+	ulp := math.Nextafter(0.5, 1) - 0.5
+	wrong := 0
+	for i := 0; i < 256; i++ {
+		for j := 0; j < 256; j++ {
+			a := Point{X: 0.5 + float64(i)*ulp, Y: 0.5 + float64(j)*ulp}
+			if sign(Orient2D(a, b, c)) != exactOrient(a, b, c) {
+				wrong++
+			}
+		}
+	}
+	if wrong > 0 {
+		t.Errorf("%d of %d orientations wrong", wrong, 256*256)
+	}
+}
+
+func TestInCircle(t *testing.T) {
+	a, b, c := Point{X: 1, Y: 0}, Point{X: 0, Y: 1}, Point{X: -1, Y: 0}
+	offset := Point{X: 654321.25, Y: 5432109.75} // UTM sized coordinates
+	move := func(p Point) Point { return Point{X: p.X + offset.X, Y: p.Y + offset.Y} }
+	ulp := math.Nextafter(1, 2) - 1
+	r := rand.New(rand.NewSource(15))
+	wrong := 0
+	for k := 0; k < 20000; k++ {
+		angle := r.Float64() * 2 * math.Pi
+		d := Point{X: math.Cos(angle) + float64(r.Intn(9)-4)*ulp, Y: math.Sin(angle) + float64(r.Intn(9)-4)*ulp}
+		if sign(InCircle(a, b, c, d)) != exactInCircle(a, b, c, d) {
+			wrong++
+		}
+		if ma, mb, mc, md := move(a), move(b), move(c), move(d); sign(InCircle(ma, mb, mc, md)) != exactInCircle(ma, mb, mc, md) {
+			wrong++
+		}
+	}
+	if wrong > 0 {
+		t.Errorf("%d of %d in-circle tests wrong", wrong, 40000)
+	}
+	if s := InCircle(a, b, c, Point{X: 0, Y: -1}); s != 0 {
+		t.Errorf("point on the circle gives %v", s)
+	}
+}
+
+func TestTriangulatePolygonEpsilon(t *testing.T) {
+	r := rand.New(rand.NewSource(1500))
+	for k := 0; k < 1500; k++ {
+		data := make([]byte, 6+r.Intn(30))
+		r.Read(data)
+		ring := monotoneRing(data)
+		want := math.Abs(signedArea(ring))
+		// points almost on the edges, slightly in or out
+		var points []Point
+		for i, p := range ring {
+			q := ring[(i+1)%len(ring)]
+			points = append(points, p)
+			if r.Intn(2) == 0 {
+				shift := (r.Float64() - 0.5) * 1e-7
+				points = append(points, Point{X: (p.X+q.X)/2 - shift*(q.Y-p.Y), Y: (p.Y+q.Y)/2 + shift*(q.X-p.X)})
+			}
+		}
+		polygon := Polygon{Outer: closedPoly(points)}
+		for _, method := range []Method{EarClipping, ConstrainedDelaunay} {
+			triangles, err := TriangulatePolygon(polygon, Options{Method: method, Epsilon: 1e-3})
+			if err != nil {
+				t.Fatalf("method %d, ring %v: %v", method, points, err)
+			}
+			area := 0.0
+			for i := 0; i+2 < len(triangles); i += 3 {
+				area += math.Abs(signedArea(triangles[i : i+3]))
+			}
+			if math.Abs(area-want) > 1e-6 {
+				t.Fatalf("method %d, ring %v: area %v, want %v", method, points, area, want)
+			}
+		}
+	}
+}
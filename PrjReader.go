@@ -0,0 +1,270 @@
+package shpReader
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+The .prj file holds the coordinate reference system as Well Known Text (WKT), e.g.
+
+	PROJCS["WGS_1984_UTM_Zone_31N",
+	  GEOGCS["GCS_WGS_1984",
+	    DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],
+	    PRIMEM["Greenwich",0.0],
+	    UNIT["Degree",0.0174532925199433]],
+	  PROJECTION["Transverse_Mercator"],
+	  PARAMETER["False_Easting",500000.0],
+	  PARAMETER["Central_Meridian",3.0],
+	  ...
+	  UNIT["Meter",1.0]]
+
+A geographic system (GEOGCS) has lon/lat coordinates, a projected system (PROJCS) has a GEOGCS,
+a projection with parameters and a linear unit.
+*/
+
+// WKTNode is an element of WKT, Values holds strings, float64 numbers and nested nodes
+type WKTNode struct {
+	Keyword string
+	Values  []interface{}
+}
+
+// Ellipsoid is the SPHEROID of a datum
+type Ellipsoid struct {
+	Name              string
+	SemiMajorAxis     float64 // in metres
+	InverseFlattening float64 // 0 for a sphere
+}
+
+// Datum is the DATUM of a geographic coordinate system
+type Datum struct {
+	Name      string
+	Ellipsoid Ellipsoid
+	ToWGS84   []float64 // optional datum shift parameters
+}
+
+// Unit is a UNIT, Factor converts to metres for linear units and to radians for angular units
+type Unit struct {
+	Name   string
+	Factor float64
+}
+
+// CRS is the coordinate reference system of a shapefile
+type CRS struct {
+	Name          string  // name of the PROJCS or GEOGCS
+	Projected     bool    // PROJCS, else GEOGCS with lon/lat coordinates
+	GeogName      string  // name of the GEOGCS
+	Datum         Datum   //
+	PrimeMeridian float64 // longitude of the prime meridian in AngularUnit
+	AngularUnit   Unit    // unit of the GEOGCS
+	Projection    string  // PROJECTION name, empty for a geographic system
+	Parameters    map[string]float64
+	Unit          Unit // linear unit of a projected system, AngularUnit for a geographic system
+	WKT           string
+}
+
+// Parameter returns a projection parameter, the name is compared case insensitive and
+// ignoring spaces and underscores ("false_easting" finds "False_Easting")
+func (c CRS) Parameter(name string) (float64, bool) {
+	for key, value := range c.Parameters {
+		if normalizeName(key) == normalizeName(name) {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// IsGeographic reports if the coordinates are longitude and latitude
+func (c CRS) IsGeographic() bool {
+	return !c.Projected
+}
+
+func (c CRS) String() string {
+	if !c.Projected {
+		return fmt.Sprintf("%s (geographic, datum %s, ellipsoid %s, %s)", c.Name, c.Datum.Name, c.Datum.Ellipsoid.Name, c.Unit.Name)
+	}
+	return fmt.Sprintf("%s (projection %s, datum %s, ellipsoid %s, %s)", c.Name, c.Projection, c.Datum.Name, c.Datum.Ellipsoid.Name, c.Unit.Name)
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", " ", "").Replace(name))
+}
+
+// ReadPrj reads the coordinate reference system from a .prj file
+func ReadPrj(filename string) (*CRS, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWKT(string(content))
+}
+
+// ParseWKT parses a GEOGCS or PROJCS coordinate system in WKT
+func ParseWKT(wkt string) (crs *CRS, err error) {
+	p := &wktParser{s: wkt}
+	node, err := p.node()
+	if err != nil {
+		return nil, err
+	}
+	crs = &CRS{WKT: strings.TrimSpace(wkt), Parameters: map[string]float64{}}
+	geogcs := node
+	switch node.Keyword {
+	case "PROJCS":
+		crs.Projected = true
+		crs.Name = node.text(0)
+		if geogcs = node.child("GEOGCS"); geogcs == nil {
+			return nil, errors.New("PROJCS without GEOGCS")
+		}
+		if projection := node.child("PROJECTION"); projection != nil {
+			crs.Projection = projection.text(0)
+		}
+		for _, value := range node.Values {
+			if parameter, ok := value.(*WKTNode); ok && parameter.Keyword == "PARAMETER" {
+				crs.Parameters[parameter.text(0)] = parameter.number(1)
+			}
+		}
+		crs.Unit = node.unit()
+	case "GEOGCS":
+		crs.Name = node.text(0)
+	default:
+		return nil, fmt.Errorf("unsupported coordinate system %s", node.Keyword)
+	}
+	crs.GeogName = geogcs.text(0)
+	crs.AngularUnit = geogcs.unit()
+	if !crs.Projected {
+		crs.Unit = crs.AngularUnit
+	}
+	if primem := geogcs.child("PRIMEM"); primem != nil {
+		crs.PrimeMeridian = primem.number(1)
+	}
+	if datum := geogcs.child("DATUM"); datum != nil {
+		crs.Datum.Name = datum.text(0)
+		if spheroid := datum.child("SPHEROID"); spheroid != nil {
+			crs.Datum.Ellipsoid = Ellipsoid{spheroid.text(0), spheroid.number(1), spheroid.number(2)}
+		}
+		if toWGS84 := datum.child("TOWGS84"); toWGS84 != nil {
+			for i := range toWGS84.Values {
+				crs.Datum.ToWGS84 = append(crs.Datum.ToWGS84, toWGS84.number(i))
+			}
+		}
+	}
+	return crs, nil
+}
+
+// child returns the first nested node with keyword
+func (n *WKTNode) child(keyword string) *WKTNode {
+	for _, value := range n.Values {
+		if child, ok := value.(*WKTNode); ok && child.Keyword == keyword {
+			return child
+		}
+	}
+	return nil
+}
+
+// text returns value i as string, empty if it is not a string
+func (n *WKTNode) text(i int) string {
+	if i < len(n.Values) {
+		if s, ok := n.Values[i].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// number returns value i as number, 0 if it is not a number
+func (n *WKTNode) number(i int) float64 {
+	if i < len(n.Values) {
+		if f, ok := n.Values[i].(float64); ok {
+			return f
+		}
+	}
+	return 0
+}
+
+func (n *WKTNode) unit() Unit {
+	if unit := n.child("UNIT"); unit != nil {
+		return Unit{unit.text(0), unit.number(1)}
+	}
+	return Unit{}
+}
+
+// wktParser is a recursive descent parser for KEYWORD[value, ...], brackets may be [] or ()
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("WKT at %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *wktParser) node() (node *WKTNode, err error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && (unicode.IsLetter(rune(p.s[p.pos])) || unicode.IsDigit(rune(p.s[p.pos])) || p.s[p.pos] == '_') {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, p.errorf("keyword expected")
+	}
+	node = &WKTNode{Keyword: strings.ToUpper(p.s[start:p.pos])}
+	p.skipSpace()
+	if p.pos >= len(p.s) || (p.s[p.pos] != '[' && p.s[p.pos] != '(') {
+		return node, nil // keyword without values, e.g. AXIS["Lat",NORTH]
+	}
+	p.pos++
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unexpected end in %s", node.Keyword)
+		}
+		switch c := p.s[p.pos]; {
+		case c == '"':
+			end := strings.IndexByte(p.s[p.pos+1:], '"')
+			if end < 0 {
+				return nil, p.errorf("unterminated string")
+			}
+			node.Values = append(node.Values, p.s[p.pos+1:p.pos+1+end])
+			p.pos += end + 2
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			start := p.pos
+			for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+				p.pos++
+			}
+			f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			node.Values = append(node.Values, f)
+		default:
+			child, err := p.node()
+			if err != nil {
+				return nil, err
+			}
+			node.Values = append(node.Values, child)
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unexpected end in %s", node.Keyword)
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case ']', ')':
+			p.pos++
+			return node, nil
+		default:
+			return nil, p.errorf("unexpected %q in %s", p.s[p.pos], node.Keyword)
+		}
+	}
+}
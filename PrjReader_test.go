@@ -0,0 +1,51 @@
+package shpReader
+
+import (
+	"math"
+	"testing"
+)
+
+const wgs84WKT = `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],` +
+	`PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`
+
+const utm32WKT = `PROJCS["WGS_1984_UTM_Zone_32N",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",` +
+	`SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],` +
+	`PROJECTION["Transverse_Mercator"],PARAMETER["False_Easting",500000.0],PARAMETER["False_Northing",0.0],` +
+	`PARAMETER["Central_Meridian",9.0],PARAMETER["Scale_Factor",0.9996],PARAMETER["Latitude_Of_Origin",0.0],` +
+	`UNIT["Meter",1.0]]`
+
+func TestParseWKT(t *testing.T) {
+	crs, err := ParseWKT(wgs84WKT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crs.Projected || crs.Name != "GCS_WGS_1984" || crs.Datum.Ellipsoid.SemiMajorAxis != 6378137 ||
+		crs.Datum.Ellipsoid.InverseFlattening != 298.257223563 || math.Abs(crs.Unit.Factor-math.Pi/180) > 1e-15 {
+		t.Errorf("geographic system %+v", crs)
+	}
+	crs, err = ParseWKT(utm32WKT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crs.Projected || crs.Projection != "Transverse_Mercator" || crs.GeogName != "GCS_WGS_1984" || crs.Unit.Factor != 1 {
+		t.Errorf("projected system %+v", crs)
+	}
+	for name, want := range map[string]float64{"false_easting": 500000, "Central Meridian": 9, "scale_factor": 0.9996} {
+		if v, ok := crs.Parameter(name); !ok || v != want {
+			t.Errorf("parameter %s %v, want %v", name, v, want)
+		}
+	}
+}
+
+func TestParseWKTErrors(t *testing.T) {
+	for _, wkt := range []string{
+		"",
+		`GEOGCRS["WGS 84",DATUM["World Geodetic System 1984",ELLIPSOID["WGS 84",6378137,298.257223563]]]`,
+		`GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984"`,
+		`PROJCS["Unknown",PROJECTION["Mercator"]]`,
+	} {
+		if crs, err := ParseWKT(wkt); err == nil {
+			t.Errorf("%q parsed as %v", wkt, crs)
+		}
+	}
+}
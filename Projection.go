@@ -0,0 +1,323 @@
+// Projection
+/* projects longitude/latitude in degrees onto a plane in metres,
+shapes are projected before they are triangulated and translated to the screen
+*/
+package Projection
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	Shp "TriangMap/ShpReader"
+)
+
+// WGS84 ellipsoid, used by the ellipsoidal projections
+const (
+	SemiMajorAxis     = 6378137.0
+	InverseFlattening = 298.257223563
+	AuthalicRadius    = 6371007.181 // radius of the sphere with the area of the ellipsoid
+	MaxWebMercatorLat = 85.0511287798
+	MaxMercatorLat    = 89.5
+)
+
+var (
+	flattening   = 1 / InverseFlattening
+	eccentricity = math.Sqrt(flattening * (2 - flattening))
+	qPole        = authalicQ(math.Pi / 2)
+	authalic     = SemiMajorAxis * math.Sqrt(qPole/2) // AuthalicRadius without rounding
+)
+
+// Projection transforms longitude and latitude in degrees to x, y in metres
+type Projection interface {
+	Forward(lon, lat float64) (x, y float64)
+	Name() string
+}
+
+// Mercator is the conformal cylindrical projection on the WGS84 ellipsoid, latitudes are limited to MaxMercatorLat
+type Mercator struct {
+	CentralMeridian float64
+}
+
+func (p Mercator) Name() string { return "Mercator" }
+
+func (p Mercator) Forward(lon, lat float64) (x, y float64) {
+	phi := radians(clamp(lat, MaxMercatorLat))
+	esin := eccentricity * math.Sin(phi)
+	x = SemiMajorAxis * radians(lon-p.CentralMeridian)
+	y = SemiMajorAxis * math.Log(math.Tan(math.Pi/4+phi/2)*math.Pow((1-esin)/(1+esin), eccentricity/2))
+	return
+}
+
+// WebMercator is the spherical Mercator of web tiles (EPSG:3857), latitudes are limited to MaxWebMercatorLat
+type WebMercator struct{}
+
+func (p WebMercator) Name() string { return "Web Mercator" }
+
+func (p WebMercator) Forward(lon, lat float64) (x, y float64) {
+	phi := radians(clamp(lat, MaxWebMercatorLat))
+	return SemiMajorAxis * radians(lon), SemiMajorAxis * math.Log(math.Tan(math.Pi/4+phi/2))
+}
+
+// Equirectangular is the plate carrée with true scale along StandardParallel
+type Equirectangular struct {
+	CentralMeridian  float64
+	StandardParallel float64
+}
+
+func (p Equirectangular) Name() string { return "Equirectangular" }
+
+func (p Equirectangular) Forward(lon, lat float64) (x, y float64) {
+	return SemiMajorAxis * radians(lon-p.CentralMeridian) * math.Cos(radians(p.StandardParallel)), SemiMajorAxis * radians(lat)
+}
+
+// LambertConformalConic is the conformal conic projection with two standard parallels on the WGS84 ellipsoid
+type LambertConformalConic struct {
+	CentralMeridian float64
+	n, f, rho0      float64
+}
+
+// NewLambertConformalConic returns the projection with its origin at lon0, lat0,
+// the standard parallels may not be symmetric about the equator
+func NewLambertConformalConic(lon0, lat0, parallel1, parallel2 float64) (*LambertConformalConic, error) {
+	phi1, phi2 := radians(parallel1), radians(parallel2)
+	p := &LambertConformalConic{CentralMeridian: lon0}
+	if math.Abs(phi1-phi2) < 1e-10 {
+		p.n = math.Sin(phi1)
+	} else {
+		p.n = (math.Log(conicM(phi1)) - math.Log(conicM(phi2))) / (math.Log(conicT(phi1)) - math.Log(conicT(phi2)))
+	}
+	if math.Abs(p.n) < 1e-10 {
+		return nil, fmt.Errorf("standard parallels %g and %g are symmetric about the equator", parallel1, parallel2)
+	}
+	p.f = conicM(phi1) / (p.n * math.Pow(conicT(phi1), p.n))
+	p.rho0 = SemiMajorAxis * p.f * math.Pow(conicT(radians(lat0)), p.n)
+	return p, nil
+}
+
+func (p *LambertConformalConic) Name() string { return "Lambert Conformal Conic" }
+
+func (p *LambertConformalConic) Forward(lon, lat float64) (x, y float64) {
+	phi := radians(lat)
+	if math.Signbit(lat) != math.Signbit(p.n) { // the pole away from the apex of the cone is at infinity
+		phi = radians(clamp(lat, MaxMercatorLat))
+	}
+	rho := SemiMajorAxis * p.f * math.Pow(conicT(phi), p.n)
+	theta := p.n * radians(normalizeLon(lon-p.CentralMeridian))
+	return rho * math.Sin(theta), p.rho0 - rho*math.Cos(theta)
+}
+
+// conicM and conicT are m and t of Snyder, Map Projections - A Working Manual (15-9, 15-11)
+func conicM(phi float64) float64 {
+	esin := eccentricity * math.Sin(phi)
+	return math.Cos(phi) / math.Sqrt(1-esin*esin)
+}
+
+func conicT(phi float64) float64 {
+	esin := eccentricity * math.Sin(phi)
+	return math.Tan(math.Pi/4-phi/2) / math.Pow((1-esin)/(1+esin), eccentricity/2)
+}
+
+// LambertEqualArea is the Lambert azimuthal equal area projection on the WGS84 ellipsoid centred on lon0, lat0
+type LambertEqualArea struct {
+	CentralMeridian float64
+	CentralLat      float64
+}
+
+func (p LambertEqualArea) Name() string { return "Lambert Azimuthal Equal Area" }
+
+// Forward converts the latitudes to authalic latitudes, the latitudes on the sphere of AuthalicRadius with the same
+// area between them and the equator as on the ellipsoid, and projects the sphere. d stretches x and shrinks y to
+// keep the scale true in all directions at the centre (Snyder 3-11, 3-12, 24-16 to 24-19)
+func (p LambertEqualArea) Forward(lon, lat float64) (x, y float64) {
+	beta, beta1, dLon := authalicLat(radians(lat)), authalicLat(radians(p.CentralLat)), radians(lon-p.CentralMeridian)
+	d := 1.0 // at a pole
+	if math.Cos(beta1) > 1e-12 {
+		d = SemiMajorAxis * conicM(radians(p.CentralLat)) / (authalic * math.Cos(beta1))
+	}
+	cos := 1 + math.Sin(beta1)*math.Sin(beta) + math.Cos(beta1)*math.Cos(beta)*math.Cos(dLon)
+	if cos < 1e-12 { // the antipode is the outer circle of the map
+		return 0, -2 * authalic / d
+	}
+	b := authalic * math.Sqrt(2/cos)
+	return b * d * math.Cos(beta) * math.Sin(dLon), b / d * (math.Cos(beta1)*math.Sin(beta) - math.Sin(beta1)*math.Cos(beta)*math.Cos(dLon))
+}
+
+// authalicQ is q of Snyder (3-12), the area between the equator and latitude phi is proportional to it
+func authalicQ(phi float64) float64 {
+	esin := eccentricity * math.Sin(phi)
+	return (1 - eccentricity*eccentricity) * (math.Sin(phi)/(1-esin*esin) - math.Log((1-esin)/(1+esin))/(2*eccentricity))
+}
+
+// authalicLat returns the authalic latitude of phi (Snyder 3-11)
+func authalicLat(phi float64) float64 {
+	return math.Asin(math.Max(-1, math.Min(1, authalicQ(phi)/qPole)))
+}
+
+// UTM is the Universal Transverse Mercator projection for a zone (1-60) on the WGS84 ellipsoid,
+// the southern hemisphere has a false northing of 10000 km
+type UTM struct {
+	Zone  int
+	South bool
+}
+
+// UTMZone returns the zone of a longitude
+func UTMZone(lon float64) int {
+	zone := int(math.Floor((normalizeLon(lon)+180)/6)) + 1
+	if zone > 60 {
+		zone = 60
+	}
+	return zone
+}
+
+func (p UTM) Name() string {
+	if p.South {
+		return fmt.Sprintf("UTM zone %dS", p.Zone)
+	}
+	return fmt.Sprintf("UTM zone %dN", p.Zone)
+}
+
+// Forward uses the series of Snyder (8-9, 8-10), accurate within a few zones of the central meridian
+func (p UTM) Forward(lon, lat float64) (x, y float64) {
+	const k0 = 0.9996
+	e2 := eccentricity * eccentricity
+	e4, e6 := e2*e2, e2*e2*e2
+	ep2 := e2 / (1 - e2)
+	phi := radians(lat)
+	lon0 := float64(p.Zone-1)*6 - 180 + 3
+	sin, cos, tan := math.Sin(phi), math.Cos(phi), math.Tan(phi)
+	n := SemiMajorAxis / math.Sqrt(1-e2*sin*sin)
+	t := tan * tan
+	c := ep2 * cos * cos
+	a := radians(normalizeLon(lon-lon0)) * cos
+	m := SemiMajorAxis * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+	x = k0*n*(a+(1-t+c)*math.Pow(a, 3)/6+(5-18*t+t*t+72*c-58*ep2)*math.Pow(a, 5)/120) + 500000
+	y = k0 * (m + n*tan*(a*a/2+(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+(61-58*t+t*t+600*c-330*ep2)*math.Pow(a, 6)/720))
+	if p.South {
+		y += 10000000
+	}
+	return
+}
+
+// constructors of ByName, the projection is centred on the data
+var projections = map[string]func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error){
+	"mercator": func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error) {
+		return Mercator{CentralMeridian: (minLon + maxLon) / 2}, nil
+	},
+	"webmercator": func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error) {
+		return WebMercator{}, nil
+	},
+	"equirectangular": func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error) {
+		return Equirectangular{CentralMeridian: (minLon + maxLon) / 2, StandardParallel: (minLat + maxLat) / 2}, nil
+	},
+	"lambert": func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error) {
+		// standard parallels at 1/6 and 5/6 of the latitude range
+		p, err := NewLambertConformalConic((minLon+maxLon)/2, (minLat+maxLat)/2, minLat+(maxLat-minLat)/6, maxLat-(maxLat-minLat)/6)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	},
+	"equalarea": func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error) {
+		return LambertEqualArea{CentralMeridian: (minLon + maxLon) / 2, CentralLat: (minLat + maxLat) / 2}, nil
+	},
+	"utm": func(minLon, minLat, maxLon, maxLat float64, option string) (Projection, error) {
+		p := UTM{Zone: UTMZone((minLon + maxLon) / 2), South: (minLat+maxLat)/2 < 0}
+		if option == "" {
+			return p, nil
+		}
+		switch strings.ToLower(option[len(option)-1:]) {
+		case "n":
+			p.South, option = false, option[:len(option)-1]
+		case "s":
+			p.South, option = true, option[:len(option)-1]
+		}
+		zone, err := strconv.Atoi(option)
+		if err != nil || zone < 1 || zone > 60 {
+			return nil, fmt.Errorf("invalid UTM zone %q", option)
+		}
+		p.Zone = zone
+		return p, nil
+	},
+}
+
+// Names returns the names accepted by ByName
+func Names() []string {
+	names := []string{"none"}
+	for name := range projections {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ByName returns a projection centred on the data bounds in degrees, "none" (or "") returns nil.
+// The UTM zone is derived from the centre of the data or given as "utm:31" or "utm:31s"
+func ByName(name string, minLon, minLat, maxLon, maxLat float64) (Projection, error) {
+	name, option, _ := strings.Cut(strings.ToLower(strings.TrimSpace(name)), ":")
+	if name == "" || name == "none" {
+		return nil, nil
+	}
+	create, ok := projections[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown projection %q, use one of %s", name, strings.Join(Names(), ", "))
+	}
+	return create(minLon, minLat, maxLon, maxLat, option)
+}
+
+// Apply projects the coordinates and boxes of shapes and the box of header.
+// Only the generic fields of ShapeData are projected, Shape keeps the coordinates as read
+func Apply(p Projection, shapes []Shp.ShapeData, header *Shp.Header) {
+	header.MinX, header.MinY = math.Inf(1), math.Inf(1)
+	header.MaxX, header.MaxY = math.Inf(-1), math.Inf(-1)
+	for i := range shapes {
+		shape := &shapes[i]
+		shape.Box0, shape.Box1 = math.Inf(1), math.Inf(1)
+		shape.Box2, shape.Box3 = math.Inf(-1), math.Inf(-1)
+		coordinates := make([][][2]float64, len(shape.Coordinates))
+		for j, part := range shape.Coordinates {
+			coordinates[j] = make([][2]float64, len(part))
+			for k, point := range part {
+				x, y := p.Forward(point[0], point[1])
+				coordinates[j][k] = [2]float64{x, y}
+				shape.Box0, shape.Box1 = math.Min(shape.Box0, x), math.Min(shape.Box1, y)
+				shape.Box2, shape.Box3 = math.Max(shape.Box2, x), math.Max(shape.Box3, y)
+			}
+		}
+		shape.Coordinates = coordinates
+		if math.IsInf(shape.Box0, 1) { // Null shape
+			shape.Box0, shape.Box1, shape.Box2, shape.Box3 = 0, 0, 0, 0
+			continue
+		}
+		header.MinX, header.MinY = math.Min(header.MinX, shape.Box0), math.Min(header.MinY, shape.Box1)
+		header.MaxX, header.MaxY = math.Max(header.MaxX, shape.Box2), math.Max(header.MaxY, shape.Box3)
+	}
+	if math.IsInf(header.MinX, 1) {
+		header.MinX, header.MinY, header.MaxX, header.MaxY = 0, 0, 0, 0
+	}
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// clamp limits a latitude to ±max
+func clamp(lat, max float64) float64 {
+	return math.Max(-max, math.Min(max, lat))
+}
+
+// normalizeLon returns a longitude difference in -180..180
+func normalizeLon(lon float64) float64 {
+	for lon > 180 {
+		lon -= 360
+	}
+	for lon < -180 {
+		lon += 360
+	}
+	return lon
+}
@@ -0,0 +1,94 @@
+package Projection
+
+import (
+	"math"
+	"testing"
+)
+
+func TestForward(t *testing.T) {
+	lambert, err := NewLambertConformalConic(10, 50, 45, 55)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		p         Projection
+		lon, lat  float64
+		x, y, tol float64
+	}{
+		{UTM{Zone: 32}, 9, 45, 500000, 4982950.4, 0.1},
+		{UTM{Zone: 32, South: true}, 9, -45, 500000, 10000000 - 4982950.4, 0.1},
+		{UTM{Zone: 31}, 3, 0, 500000, 0, 1e-6},
+		{WebMercator{}, 180, 0, 20037508.34, 0, 0.01},
+		{WebMercator{}, 0, MaxWebMercatorLat, 0, 20037508.34, 0.01},
+		{Mercator{}, 1, 0, 111319.49, 0, 0.01},
+		{Mercator{CentralMeridian: 10}, 10, 0, 0, 0, 1e-9},
+		{Equirectangular{}, 1, 1, 111319.49, 111319.49, 0.01},
+		{UTM{Zone: 32}, 12, 45, 736446.03, 4987329.50, 0.01},
+		{UTM{Zone: 32}, 6, 60, 332705.18, 6655205.48, 0.01},
+		{UTM{Zone: 31, South: true}, 3.5, -33.9, 546228.18, 10000000 - 3751180.77, 0.01},
+		// ETRS89-LAEA (EPSG:3035) without false easting and northing, example of the EPSG guidance note 7-2
+		{LambertEqualArea{CentralMeridian: 10, CentralLat: 52}, 10, 52, 0, 0, 1e-6},
+		{LambertEqualArea{CentralMeridian: 10, CentralLat: 52}, 5, 50, 3962799.45 - 4321000, 2999718.85 - 3210000, 0.01},
+		{LambertEqualArea{CentralLat: 90}, 90, 45, 4889334.80, 0, 0.01}, // Snyder 24-18: a √(qp - q)
+		{lambert, 10, 50, 0, 0, 1e-6},
+		{lambert, 20, 60, 563301.67, 1152603.27, 0.01},
+		{lambert, 2, 40, -689227.09, -1075672.22, 0.01},
+	} {
+		x, y := test.p.Forward(test.lon, test.lat)
+		if math.Abs(x-test.x) > test.tol || math.Abs(y-test.y) > test.tol {
+			t.Errorf("%s (%v, %v) = %.2f, %.2f, want %.2f, %.2f", test.p.Name(), test.lon, test.lat, x, y, test.x, test.y)
+		}
+	}
+}
+
+// TestLambertEqualAreaArea compares the areas of projected cells of 0.01° with their areas on the ellipsoid,
+// Δλ a² (q(φ2) - q(φ1)) / 2
+func TestLambertEqualAreaArea(t *testing.T) {
+	p := LambertEqualArea{CentralMeridian: 10, CentralLat: 52}
+	const size = 0.01
+	for _, lat := range []float64{0, 30, 52, 70, -40} {
+		for _, lon := range []float64{10, 25, -5} {
+			corners := [][2]float64{{lon, lat}, {lon + size, lat}, {lon + size, lat + size}, {lon, lat + size}}
+			area := 0.0
+			for i, c := range corners {
+				n := corners[(i+1)%len(corners)]
+				x1, y1 := p.Forward(c[0], c[1])
+				x2, y2 := p.Forward(n[0], n[1])
+				area += (x1*y2 - x2*y1) / 2
+			}
+			want := radians(size) * SemiMajorAxis * SemiMajorAxis * (authalicQ(radians(lat+size)) - authalicQ(radians(lat))) / 2
+			if math.Abs(area/want-1) > 1e-6 {
+				t.Errorf("cell at %g, %g: area %.2f m², want %.2f", lon, lat, area, want)
+			}
+		}
+	}
+}
+
+func TestUTMZone(t *testing.T) {
+	for lon, zone := range map[float64]int{-180: 1, -177: 1, 0: 31, 9: 32, 179.9: 60, 180: 60} {
+		if z := UTMZone(lon); z != zone {
+			t.Errorf("zone of %v is %d, want %d", lon, z, zone)
+		}
+	}
+}
+
+func TestByName(t *testing.T) {
+	p, err := ByName("utm:32s", 0, 0, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if utm, ok := p.(UTM); !ok || utm.Zone != 32 || !utm.South {
+		t.Errorf("utm:32s is %v", p)
+	}
+	if p, err = ByName("utm", 8, 44, 10, 46); err != nil || p.Name() != "UTM zone 32N" {
+		t.Errorf("utm is %v, %v", p, err)
+	}
+	if p, err = ByName("none", 0, 0, 1, 1); p != nil || err != nil {
+		t.Errorf("none is %v, %v", p, err)
+	}
+	for _, name := range []string{"utm:61", "utm:x", "gnomonic"} {
+		if _, err = ByName(name, 0, 0, 1, 1); err == nil {
+			t.Errorf("%s accepted", name)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package Triangulate
+
+import (
+	"fmt"
+	"math"
+)
+
+// GetRefinedTriangles returns a constrained Delaunay triangulation of a polygon with holes in which no angle is
+// smaller than minAngle degrees and no triangle is larger than maxArea, 0 leaves a constraint out. Every 3 points form
+// a counter-clockwise triangle. Points are added on the edges of the rings and at the circumcentres of bad triangles
+// (J. Ruppert, A Delaunay Refinement Algorithm for Quality 2-Dimensional Mesh Generation), the Z and M values of the
+// new points are interpolated. The rings keep their shape and holes stay empty.
+// Angles between 2 edges of the rings that are smaller than minAngle can not be removed, they are left as they are.
+// Minimum angles up to about 30 degrees are reached, for larger angles the refinement may stop with an error
+func GetRefinedTriangles(polygon Polygon, minAngle, maxArea float64) (triangles []Point, err error) {
+	mesh, err := constrainedMesh(polygon)
+	if mesh == nil {
+		return nil, err
+	}
+	if e := mesh.refine(minAngle, maxArea); e != nil && err == nil {
+		err = e
+	}
+	return mesh.interiorPoints(), err
+}
+
+// segment is a constrained edge that is split when a point is inside its diametral circle, or always when force is set
+type segment struct {
+	a, b  int
+	force bool
+}
+
+// refine adds points until the triangles inside the constrained edges are good: encroached segments are split
+// first, then the circumcentre of a bad triangle is added unless it encroaches segments, those are split instead
+func (m *triMesh) refine(minAngle, maxArea float64) error {
+	m.inside = make([]bool, len(m.tris))
+	defer func() { m.inside = nil }()
+	area := 0.0
+	for _, t := range m.interior() {
+		m.inside[t] = true
+		area += cross(m.points[m.tris[t][0]], m.points[m.tris[t][1]], m.points[m.tris[t][2]]) / 2
+	}
+	// the ratio of circumradius and shortest edge of a triangle with smallest angle minAngle
+	ratio := math.Inf(1)
+	if minAngle > 0 {
+		ratio = 1 / (2 * math.Sin(math.Min(minAngle, 60)*math.Pi/180))
+	}
+	input := len(m.points) // points of the rings, the later points are added by refine
+	limit := 10*input + 100000
+	if maxArea > 0 {
+		limit += int(math.Min(4*area/maxArea, 1e8))
+	}
+	var segments []segment
+	var bad [][3]int
+	for t, tri := range m.tris {
+		if !m.inside[t] {
+			continue
+		}
+		bad = append(bad, tri)
+		for i := 0; i < 3; i++ {
+			if m.fixed[t][i] {
+				segments = append(segments, segment{a: tri[i], b: tri[(i+1)%3]})
+			}
+		}
+	}
+	for len(segments) > 0 || len(bad) > 0 {
+		if len(m.points)-input > limit {
+			return fmt.Errorf("refinement stopped after %d points", len(m.points)-input)
+		}
+		if n := len(segments); n > 0 {
+			s := segments[n-1]
+			segments = segments[:n-1]
+			if !s.force && !m.encroached(s.a, s.b) {
+				continue
+			}
+			if v := m.splitSegment(s.a, s.b, input); v >= 0 {
+				segments = append(segments, segment{a: s.a, b: v}, segment{a: v, b: s.b})
+				segments, bad = m.around(v, segments, bad)
+			}
+			continue
+		}
+		tri := bad[len(bad)-1]
+		bad = bad[:len(bad)-1]
+		t := m.findTriangle(tri)
+		if t < 0 || !m.inside[t] || !m.badTriangle(t, ratio, maxArea) {
+			continue
+		}
+		c := circumcentre(m.points[tri[0]], m.points[tri[1]], m.points[tri[2]])
+		if _, ok := m.index[[2]float64{c.X, c.Y}]; ok {
+			continue // the circumcentre is on a point, too small to refine in float64
+		}
+		u, blocked := m.walk(t, c)
+		encroached := m.encroachedBy(u, c)
+		if blocked != nil {
+			encroached = append(encroached, *blocked)
+		}
+		if len(encroached) > 0 {
+			segments = append(segments, encroached...)
+			bad = append(bad, tri) // tried again when the segments are split
+			continue
+		}
+		tc := m.tris[u]
+		m.last = u
+		v := m.insert(interpolate(m.points[tc[0]], m.points[tc[1]], m.points[tc[2]], c))
+		segments, bad = m.around(v, segments, bad)
+	}
+	return nil
+}
+
+// badTriangle reports if triangle t is larger than maxArea, or if the ratio of its circumradius and its shortest edge
+// is larger than ratio and the smallest angle is not between 2 constrained edges
+func (m *triMesh) badTriangle(t int, ratio, maxArea float64) bool {
+	a, b, c := m.points[m.tris[t][0]], m.points[m.tris[t][1]], m.points[m.tris[t][2]]
+	area := cross(a, b, c) / 2
+	if area <= 0 {
+		return false
+	}
+	if maxArea > 0 && area > maxArea {
+		return true
+	}
+	lengths := [3]float64{math.Hypot(b.X-a.X, b.Y-a.Y), math.Hypot(c.X-b.X, c.Y-b.Y), math.Hypot(a.X-c.X, a.Y-c.Y)}
+	shortest := 0
+	for i := range lengths {
+		if lengths[i] < lengths[shortest] {
+			shortest = i
+		}
+	}
+	// the smallest angle is opposite the shortest edge, between the other 2 edges
+	if m.fixed[t][(shortest+1)%3] && m.fixed[t][(shortest+2)%3] {
+		return false
+	}
+	radius := lengths[0] * lengths[1] * lengths[2] / (4 * area)
+	return radius/lengths[shortest] > ratio
+}
+
+// encroached reports if the corner opposite the constrained edge a-b of a triangle inside is in the diametral
+// circle of the edge
+func (m *triMesh) encroached(a, b int) bool {
+	t, i := m.findEdge(a, b)
+	if t < 0 {
+		if t, i = m.findEdge(b, a); t < 0 {
+			return false
+		}
+	}
+	for _, side := range []int{t, m.adj[t][i]} {
+		if side < 0 || !m.inside[side] {
+			continue
+		}
+		for _, v := range m.tris[side] {
+			if v != a && v != b && inDiametralCircle(m.points[a], m.points[b], m.points[v]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// encroachedBy returns the constrained edges that would be encroached by p when it is added inside triangle t,
+// the edges around the triangles whose circumcircle contains p
+func (m *triMesh) encroachedBy(t int, p Point) (encroached []segment) {
+	visited := map[int]bool{t: true}
+	stack := []int{t}
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		tri := m.tris[u]
+		for i, w := range m.adj[u] {
+			a, b := tri[i], tri[(i+1)%3]
+			if m.fixed[u][i] {
+				if inDiametralCircle(m.points[a], m.points[b], p) {
+					encroached = append(encroached, segment{a: a, b: b, force: true})
+				}
+				continue
+			}
+			if w < 0 || visited[w] {
+				continue
+			}
+			wt := m.tris[w]
+			if inCircle(m.points[wt[0]], m.points[wt[1]], m.points[wt[2]], p) > 0 {
+				visited[w] = true
+				stack = append(stack, w)
+			}
+		}
+	}
+	return
+}
+
+// walk returns the triangle that contains p, walking from triangle t. When the walk has to cross a constrained
+// edge the edge is returned as well, p is then not visible from t
+func (m *triMesh) walk(t int, p Point) (int, *segment) {
+	for steps := 0; steps < len(m.tris); steps++ {
+		next := -1
+		for i := 0; i < 3; i++ {
+			a, b := m.tris[t][i], m.tris[t][(i+1)%3]
+			if cross(m.points[a], m.points[b], p) < 0 {
+				if m.fixed[t][i] {
+					return t, &segment{a: a, b: b, force: true}
+				}
+				next = m.adj[t][i]
+				break
+			}
+		}
+		if next < 0 {
+			return t, nil
+		}
+		t = next
+	}
+	return t, nil
+}
+
+// splitSegment adds a point on the constrained edge a-b, the halves stay constrained. The middle is used, except for
+// an edge between a point of the rings and an added point: that edge is split at a power of 2 distance from the point
+// of the rings, so edges that meet at a small angle are split at the same distances and stop encroaching each other.
+// It returns -1 when the edge is too short to split
+func (m *triMesh) splitSegment(a, b, input int) int {
+	t, i := m.findEdge(a, b)
+	if t < 0 {
+		if t, i = m.findEdge(b, a); t < 0 {
+			return -1
+		}
+	}
+	pa, pb := m.points[a], m.points[b]
+	f, length := 0.5, math.Hypot(pb.X-pa.X, pb.Y-pa.Y)
+	if (a < input) != (b < input) {
+		d := math.Exp2(math.Round(math.Log2(length / 2)))
+		if f = d / length; b < input {
+			f = 1 - f
+		}
+	}
+	p := Point{X: pa.X + f*(pb.X-pa.X), Y: pa.Y + f*(pb.Y-pa.Y), Z: pa.Z + f*(pb.Z-pa.Z), M: pa.M + f*(pb.M-pa.M)}
+	key := [2]float64{p.X, p.Y}
+	if _, ok := m.index[key]; ok {
+		return -1
+	}
+	v := len(m.points)
+	m.points = append(m.points, p)
+	m.vt = append(m.vt, t)
+	m.index[key] = v
+	m.splitEdge(t, i, v) // the rounded point is used as if it were on the edge
+	return v
+}
+
+// around adds the triangles inside around point v to bad and the constrained edges opposite v to segments
+func (m *triMesh) around(v int, segments []segment, bad [][3]int) ([]segment, [][3]int) {
+	start := m.vt[v]
+	t := start
+	for n := 0; n < len(m.tris); n++ {
+		k := 0
+		for m.tris[t][k] != v {
+			k++
+		}
+		if m.inside[t] {
+			bad = append(bad, m.tris[t])
+			if m.fixed[t][(k+1)%3] {
+				segments = append(segments, segment{a: m.tris[t][(k+1)%3], b: m.tris[t][(k+2)%3]})
+			}
+		}
+		if t = m.adj[t][(k+2)%3]; t < 0 || t == start {
+			break
+		}
+	}
+	return segments, bad
+}
+
+// findTriangle returns the triangle with corners tri in this order, -1 when it no longer exists
+func (m *triMesh) findTriangle(tri [3]int) int {
+	t, i := m.findEdge(tri[0], tri[1])
+	if t < 0 || m.tris[t][(i+2)%3] != tri[2] {
+		return -1
+	}
+	return t
+}
+
+// inherit marks new triangles inside when triangle t is inside, while the mesh is refined
+func (m *triMesh) inherit(t int, triangles ...int) {
+	if m.inside == nil {
+		return
+	}
+	for len(m.inside) < len(m.tris) {
+		m.inside = append(m.inside, false)
+	}
+	for _, u := range triangles {
+		m.inside[u] = m.inside[t]
+	}
+}
+
+// inDiametralCircle reports if p is inside the circle with diameter a-b
+func inDiametralCircle(a, b, p Point) bool {
+	return (a.X-p.X)*(b.X-p.X)+(a.Y-p.Y)*(b.Y-p.Y) < 0
+}
+
+// circumcentre returns the centre of the circle through a, b and c
+func circumcentre(a, b, c Point) Point {
+	bx, by, cx, cy := b.X-a.X, b.Y-a.Y, c.X-a.X, c.Y-a.Y
+	d := 2 * (bx*cy - by*cx)
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	return Point{X: a.X + (cy*b2-by*c2)/d, Y: a.Y + (bx*c2-cx*b2)/d}
+}
+
+// interpolate returns p with the Z and M values of the plane through triangle a, b, c
+func interpolate(a, b, c, p Point) Point {
+	area := cross(a, b, c)
+	if area == 0 {
+		return p
+	}
+	wa, wb := cross(b, c, p)/area, cross(c, a, p)/area
+	wc := 1 - wa - wb
+	p.Z = wa*a.Z + wb*b.Z + wc*c.Z
+	p.M = wa*a.M + wb*b.M + wc*c.M
+	return p
+}
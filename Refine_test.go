@@ -0,0 +1,76 @@
+package Triangulate
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// inputAngles returns the angle in degrees inside the polygon at every point of its rings
+func inputAngles(polygon Polygon) map[[2]float64]float64 {
+	angles := make(map[[2]float64]float64)
+	rings := [][]Point{orientedRing(polygon.Outer.P, true)}
+	for _, hole := range polygon.Holes {
+		rings = append(rings, orientedRing(hole.P, false)) // the polygon is right of all rings
+	}
+	for _, ring := range rings {
+		for i, p := range ring {
+			a, b := ring[(i+len(ring)-1)%len(ring)], ring[(i+1)%len(ring)]
+			angle := cornerAngle(a, p, b)
+			if cross(a, p, b) > 0 {
+				angle = 360 - angle
+			}
+			angles[[2]float64{p.X, p.Y}] = angle
+		}
+	}
+	return angles
+}
+
+// cornerAngle returns the angle at p between the edges to a and b in degrees
+func cornerAngle(a, p, b Point) float64 {
+	return math.Abs(math.Atan2(cross(p, a, b), (a.X-p.X)*(b.X-p.X)+(a.Y-p.Y)*(b.Y-p.Y))) * 180 / math.Pi
+}
+
+// checkRefined checks the triangles of GetRefinedTriangles: no angle is smaller than minAngle except at the corners
+// of the rings that are already smaller, no triangle is larger than maxArea, the area and the holes are kept
+func checkRefined(t *testing.T, name string, polygon Polygon, minAngle, maxArea float64) {
+	refine := func(polygon Polygon) ([]Point, error) { return GetRefinedTriangles(polygon, minAngle, maxArea) }
+	checkPolygonTriangles(t, name, polygon, refine, 1)
+	triangles, _ := refine(polygon)
+	angles := inputAngles(polygon)
+	for i := 0; i+2 < len(triangles); i += 3 {
+		corners := triangles[i : i+3]
+		if area := cross(corners[0], corners[1], corners[2]) / 2; maxArea > 0 && area > maxArea {
+			t.Fatalf("%s: triangle %v has area %v, want at most %v", name, corners, area, maxArea)
+		}
+		for j, p := range corners {
+			if input, ok := angles[[2]float64{p.X, p.Y}]; ok && input < minAngle+1e-9 {
+				continue
+			}
+			if angle := cornerAngle(corners[(j+2)%3], p, corners[(j+1)%3]); angle < minAngle-1e-9 {
+				t.Fatalf("%s: triangle %v has an angle of %v degrees at %v, want at least %v", name, corners, angle, p, minAngle)
+			}
+		}
+	}
+}
+
+func TestGetRefinedTriangles(t *testing.T) {
+	withHole := Polygon{Outer: square(0, 0, 10), Holes: []*Poly{reversedSquare(3, 3, 4)}}
+	checkRefined(t, "hole", withHole, 30, 0)
+	checkRefined(t, "hole area", withHole, 0, 0.5)
+	checkRefined(t, "hole both", withHole, 25, 2)
+	checkRefined(t, "grid", holeGrid(), 30, 1)
+	// a sharp corner of 11 degrees stays
+	sharp := Polygon{Outer: closedPoly([]Point{{X: 0, Y: 0}, {X: 1, Y: 5}, {X: 10, Y: 0}})}
+	checkRefined(t, "sharp", sharp, 25, 0)
+	r := rand.New(rand.NewSource(21))
+	for k := 0; k < 20; k++ {
+		checkRefined(t, "star", starWithHoles(r), 20, 1)
+	}
+
+	// the triangles of a square are already good for 40 degrees, the holes of the grid are not
+	if _, err := GetRefinedTriangles(holeGrid(), 40, 0); err == nil || !strings.Contains(err.Error(), "refinement stopped") {
+		t.Errorf("minimum angle 40: error %v, want refinement stopped", err)
+	}
+}
@@ -0,0 +1,436 @@
+package Triangulate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ProblemKind is the kind of defect Validate finds and Repair fixes
+type ProblemKind int
+
+const (
+	TooFewPoints     ProblemKind = iota // ring with less than 3 different points, it is removed
+	DuplicatePoint                      // repeated point, a zero-length edge, it is removed
+	Spike                               // the ring goes back along the edge it came from, the tip is removed
+	SelfIntersection                    // 2 edges of a ring cross, the ring is split at the intersection point
+	SelfTouch                           // a point of the ring is on another edge or equal to another point, the ring is split there
+	RingIntersection                    // edges of 2 rings cross, the rings are split at the intersection point
+	NoArea                              // an edge on top of another edge, the edges enclose no area and are removed
+)
+
+var problemNames = [...]string{"too few points", "duplicate point", "spike", "self-intersection", "self-touch", "ring intersection", "no area"}
+
+func (k ProblemKind) String() string {
+	if int(k) < len(problemNames) {
+		return problemNames[k]
+	}
+	return fmt.Sprintf("problem %d", int(k))
+}
+
+// Problem is a defect of ring Ring of a polygon, 0 is the outer ring and 1 the first hole, at point Point
+type Problem struct {
+	Kind  ProblemKind
+	Ring  int
+	Point Point
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("ring %d: %s at %v, %v", p.Ring, p.Kind, p.Point.X, p.Point.Y)
+}
+
+// repairEdge is an edge of ring number ring
+type repairEdge struct {
+	a, b Point
+	ring int
+}
+
+// Validate returns the problems of a polygon that Repair fixes, a valid polygon has none
+func Validate(polygon Polygon) []Problem {
+	_, problems := cleanEdges(polygon)
+	return problems
+}
+
+// Repair returns valid polygons that cover the area of a polygon and the problems that were fixed.
+// Duplicate points and spikes are removed, edges are split where they cross or touch, and edges that lie on top of
+// each other cancel out. The area is the even-odd area of all rings: a point is inside when a line from the point
+// to infinity crosses the rings an odd number of times, so a bow-tie becomes 2 polygons and a hole that crosses the
+// outer ring cuts it. The orientation of the input rings is not used, the result has clockwise outer rings and
+// counter-clockwise holes. The area is found with a constrained Delaunay triangulation of the edges, the rings are
+// the edges between the triangles inside and outside. Where the area touches itself in a point the rings are split,
+// so the result has no problems for Validate: a ring that touches itself becomes 2 polygons or a polygon with a hole
+// that touches the outer ring in a point
+func Repair(polygon Polygon) (polygons []Polygon, problems []Problem, err error) {
+	edges, problems := cleanEdges(polygon)
+	if len(edges) == 0 {
+		return nil, problems, nil
+	}
+	points := make([]Point, 0, 2*len(edges))
+	for _, e := range edges {
+		points = append(points, e.a, e.b)
+	}
+	mesh := newTriMesh(points)
+	for _, p := range points {
+		mesh.insert(p)
+	}
+	for _, e := range edges {
+		if e := mesh.insertConstraint(mesh.insert(e.a), mesh.insert(e.b)); e != nil && err == nil {
+			err = e
+		}
+	}
+	return assemblePolygons(mesh.boundaries(mesh.interior())), problems, err
+}
+
+// assemblePolygons turns the boundaries of an area into polygons: counter-clockwise rings are outer rings, a clockwise
+// ring is a hole of the smallest outer ring around it. The polygons have clockwise outer rings and counter-clockwise
+// holes like a shapefile
+func assemblePolygons(rings [][]Point) (polygons []Polygon) {
+	var holes [][]Point
+	for _, ring := range rings {
+		if signedArea(ring) > 0 { // counter-clockwise around the area
+			polygons = append(polygons, Polygon{Outer: closedPoly(orientedRing(ring, true))})
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+	for _, hole := range holes {
+		inner, best, bestArea := interiorPoint(hole), -1, math.MaxFloat64
+		for i, polygon := range polygons {
+			if area := math.Abs(signedArea(polygon.Outer.P)); area < bestArea && insideRing(inner, polygon.Outer.P) {
+				best, bestArea = i, area
+			}
+		}
+		if best >= 0 {
+			polygons[best].Holes = append(polygons[best].Holes, closedPoly(orientedRing(hole, false)))
+		}
+	}
+	return
+}
+
+// cleanEdges returns the edges of the rings of a polygon without duplicate points and spikes, split where they cross
+// or touch other edges. Edges that are on top of each other are removed in pairs
+func cleanEdges(polygon Polygon) (edges []repairEdge, problems []Problem) {
+	rings := append([]*Poly{polygon.Outer}, polygon.Holes...)
+	for r, ring := range rings {
+		if ring == nil {
+			continue
+		}
+		points, found := cleanRing(ring.P, r)
+		problems = append(problems, found...)
+		if len(points) < 3 {
+			at := Point{}
+			if len(ring.P) > 0 {
+				at = ring.P[0]
+			}
+			problems = append(problems, Problem{Kind: TooFewPoints, Ring: r, Point: at})
+			continue
+		}
+		seen := make(map[[2]float64]bool, len(points))
+		for i, p := range points {
+			if key := [2]float64{p.X, p.Y}; seen[key] {
+				problems = append(problems, Problem{Kind: SelfTouch, Ring: r, Point: p})
+			} else {
+				seen[key] = true
+			}
+			edges = append(edges, repairEdge{p, points[(i+1)%len(points)], r})
+		}
+	}
+	edges, found := splitAllEdges(edges)
+	problems = append(problems, found...)
+	// an edge that is used twice encloses no area
+	same := make(map[[4]float64][]int)
+	var keys [][4]float64
+	for i, e := range edges {
+		key := [4]float64{e.a.X, e.a.Y, e.b.X, e.b.Y}
+		if e.b.X < e.a.X || (e.b.X == e.a.X && e.b.Y < e.a.Y) {
+			key = [4]float64{e.b.X, e.b.Y, e.a.X, e.a.Y}
+		}
+		if same[key] == nil {
+			keys = append(keys, key)
+		}
+		same[key] = append(same[key], i)
+	}
+	kept := make([]repairEdge, 0, len(edges))
+	for _, key := range keys {
+		list := same[key]
+		for i := 1; i < len(list); i += 2 {
+			problems = append(problems, Problem{Kind: NoArea, Ring: edges[list[i]].ring, Point: edges[list[i]].a})
+		}
+		if len(list)%2 == 1 {
+			kept = append(kept, edges[list[0]])
+		}
+	}
+	return kept, problems
+}
+
+// cleanRing returns the points of a ring without closing point, duplicate points and spikes
+func cleanRing(ring []Point, r int) (points []Point, problems []Problem) {
+	points = make([]Point, 0, len(ring))
+	for _, p := range ring {
+		p.UnDelete()
+		points = append(points, p)
+	}
+	if len(points) > 1 && equalXY(points[0], points[len(points)-1]) {
+		points = points[:len(points)-1]
+	}
+	for changed := true; changed && len(points) > 0; {
+		changed = false
+		kept := points[:0]
+		for _, p := range points {
+			n := len(kept)
+			if n > 0 && equalXY(kept[n-1], p) {
+				problems = append(problems, Problem{Kind: DuplicatePoint, Ring: r, Point: p})
+				changed = true
+				continue
+			}
+			kept = append(kept, p)
+			if n >= 2 && isSpike(kept[n-2], kept[n-1], p) {
+				problems = append(problems, Problem{Kind: Spike, Ring: r, Point: kept[n-1]})
+				kept[n-1] = p
+				kept = kept[:n]
+				changed = true
+			}
+		}
+		points = kept
+		// the points where the ring closes
+		if n := len(points); n >= 2 && equalXY(points[0], points[n-1]) {
+			problems = append(problems, Problem{Kind: DuplicatePoint, Ring: r, Point: points[n-1]})
+			points, changed = points[:n-1], true
+		} else if n >= 3 && isSpike(points[n-2], points[n-1], points[0]) {
+			problems = append(problems, Problem{Kind: Spike, Ring: r, Point: points[n-1]})
+			points, changed = points[:n-1], true
+		} else if n >= 3 && isSpike(points[n-1], points[0], points[1]) {
+			problems = append(problems, Problem{Kind: Spike, Ring: r, Point: points[0]})
+			points, changed = points[1:], true
+		}
+	}
+	return
+}
+
+// cut is a point that is added to an edge, t is its position along the edge
+type cut struct {
+	t float64
+	p Point
+}
+
+// splitEdges splits edges at the points where they cross other edges and at the end points of other edges
+// that are on them. Crossings and points on edges of the same ring are reported
+func splitEdges(edges []repairEdge) (split []repairEdge, problems []Problem) {
+	cuts := make([][]cut, len(edges))
+	add := func(i int, p Point) {
+		a, b := edges[i].a, edges[i].b
+		dx, dy := b.X-a.X, b.Y-a.Y
+		t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / (dx*dx + dy*dy)
+		p.Z, p.M = a.Z+t*(b.Z-a.Z), a.M+t*(b.M-a.M)
+		cuts[i] = append(cuts[i], cut{t, p})
+	}
+	// p with orientation o against edge i is on the edge between its end points
+	touch := func(i int, p Point, o float64, ring int) {
+		a, b := edges[i].a, edges[i].b
+		if o == 0 && onSegment(a, b, p) && !equalXY(p, a) && !equalXY(p, b) {
+			add(i, p)
+			if ring == edges[i].ring {
+				problems = append(problems, Problem{Kind: SelfTouch, Ring: ring, Point: p})
+			}
+		}
+	}
+	// sweep the edges from left to right, only edges that overlap in X are compared
+	order := make([]int, len(edges))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return math.Min(edges[order[i]].a.X, edges[order[i]].b.X) < math.Min(edges[order[j]].a.X, edges[order[j]].b.X)
+	})
+	for k, i := range order {
+		a, b := edges[i].a, edges[i].b
+		maxX := math.Max(a.X, b.X)
+		for _, j := range order[k+1:] {
+			c, d := edges[j].a, edges[j].b
+			if math.Min(c.X, d.X) > maxX {
+				break
+			}
+			if math.Min(a.Y, b.Y) > math.Max(c.Y, d.Y) || math.Min(c.Y, d.Y) > math.Max(a.Y, b.Y) {
+				continue
+			}
+			o1, o2, o3, o4 := cross(a, b, c), cross(a, b, d), cross(c, d, a), cross(c, d, b)
+			if sign(o1)*sign(o2) < 0 && sign(o3)*sign(o4) < 0 {
+				t := o3 / (o3 - o4)
+				x := Point{X: a.X + t*(b.X-a.X), Y: a.Y + t*(b.Y-a.Y)}
+				add(i, x)
+				add(j, x)
+				kind := SelfIntersection
+				if edges[i].ring != edges[j].ring {
+					kind = RingIntersection
+				}
+				problems = append(problems, Problem{Kind: kind, Ring: edges[i].ring, Point: x})
+				continue
+			}
+			touch(i, c, o1, edges[j].ring)
+			touch(i, d, o2, edges[j].ring)
+			touch(j, a, o3, edges[i].ring)
+			touch(j, b, o4, edges[i].ring)
+		}
+	}
+	for i, e := range edges {
+		sort.Slice(cuts[i], func(x, y int) bool { return cuts[i][x].t < cuts[i][y].t })
+		from := e.a
+		for _, c := range cuts[i] {
+			if !equalXY(c.p, from) && !equalXY(c.p, e.b) {
+				split = append(split, repairEdge{from, c.p, e.ring})
+				from = c.p
+			}
+		}
+		split = append(split, repairEdge{from, e.b, e.ring})
+	}
+	return
+}
+
+// splitAllEdges splits edges with splitEdges until no edges cross or touch. A crossing point is rounded, so the parts
+// of the crossing edges can cross other edges close to it. Only the problems of the input edges are reported
+func splitAllEdges(edges []repairEdge) (split []repairEdge, problems []Problem) {
+	split, problems = splitEdges(edges)
+	for pass, found := 0, problems; pass < 10 && len(found) > 0; pass++ {
+		split, found = splitEdges(split)
+	}
+	return
+}
+
+// boundaries returns the rings around a set of triangles, counter-clockwise around the triangles and clockwise
+// around the holes between them. Where a ring meets itself in a point it turns to the edge that keeps the
+// triangles on its left, so the rings never cross, and the loop back to the point becomes a ring of its own
+func (m *triMesh) boundaries(triangles []int) (rings [][]Point) {
+	in := make(map[int]bool, len(triangles))
+	for _, t := range triangles {
+		in[t] = true
+	}
+	out := make(map[int][]int) // the end points of the boundary edges from a point
+	var starts [][2]int
+	for _, t := range triangles {
+		for i, u := range m.adj[t] {
+			if u < 0 || !in[u] {
+				a, b := m.tris[t][i], m.tris[t][(i+1)%3]
+				out[a] = append(out[a], b)
+				starts = append(starts, [2]int{a, b})
+			}
+		}
+	}
+	// next returns the edge after edge a -> b, the first edge from b clockwise from the direction back to a
+	next := func(a, b int) [2]int {
+		best := -1
+		for _, c := range out[b] {
+			if best < 0 || clockwiseBefore(m.points[b], m.points[a], m.points[c], m.points[best]) {
+				best = c
+			}
+		}
+		return [2]int{b, best}
+	}
+	used := make(map[[2]int]bool, len(starts))
+	for _, start := range starts {
+		if used[start] {
+			continue
+		}
+		var ring []int
+		at := make(map[int]int) // position of a point in ring
+		for e := start; !used[e]; e = next(e[0], e[1]) {
+			used[e] = true
+			if i, ok := at[e[0]]; ok {
+				rings = m.appendRing(rings, ring[i:])
+				for _, v := range ring[i:] {
+					delete(at, v)
+				}
+				ring = ring[:i]
+			}
+			at[e[0]] = len(ring)
+			ring = append(ring, e[0])
+		}
+		rings = m.appendRing(rings, ring)
+	}
+	return
+}
+
+// appendRing appends the points of a ring of at least 3 points to rings
+func (m *triMesh) appendRing(rings [][]Point, ring []int) [][]Point {
+	if len(ring) < 3 {
+		return rings
+	}
+	points := make([]Point, len(ring))
+	for i, v := range ring {
+		points[i] = m.points[v]
+	}
+	return append(rings, points)
+}
+
+// clockwiseBefore reports if the direction from o to c comes before the direction from o to d when turning clockwise
+// from the direction from o to a, the direction to a itself comes last. The order is decided with Orient2D and the
+// signs of coordinate differences, so it is exact
+func clockwiseBefore(o, a, c, d Point) bool {
+	if hc, hd := clockwiseHalf(o, a, c), clockwiseHalf(o, a, d); hc != hd {
+		return hc < hd
+	}
+	return cross(o, c, d) < 0
+}
+
+// clockwiseHalf is 0 when the direction from o to c is more than 0 and at most 180 degrees clockwise from the
+// direction from o to a, otherwise 1
+func clockwiseHalf(o, a, c Point) int {
+	switch sign(cross(o, a, c)) {
+	case -1:
+		return 0
+	case 1:
+		return 1
+	}
+	// on the line through o and a, 180 degrees when c is on the other side of o
+	if sign(c.X-o.X) != sign(a.X-o.X) || sign(c.Y-o.Y) != sign(a.Y-o.Y) {
+		return 0
+	}
+	return 1
+}
+
+// interiorPoint returns a point inside a simple ring: the middle of a convex corner when no point of the ring is
+// inside the triangle of the corner, otherwise the middle between the corner and the point inside it that is furthest
+// from the line between the neighbours of the corner, that point is visible from the corner (J. O'Rourke)
+func interiorPoint(ring []Point) Point {
+	v := 0
+	for i, p := range ring { // the lowest point is a convex corner
+		if p.Y < ring[v].Y || (p.Y == ring[v].Y && p.X < ring[v].X) {
+			v = i
+		}
+	}
+	n := len(ring)
+	a, b, c := ring[(v+n-1)%n], ring[v], ring[(v+1)%n]
+	furthest, best := -1, 0.0
+	for i, p := range ring {
+		if i == v || equalXY(p, a) || equalXY(p, b) || equalXY(p, c) || !inOrOnTriangle(a, b, c, p) {
+			continue
+		}
+		if d := math.Abs(cross(a, c, p)); furthest < 0 || d > best {
+			furthest, best = i, d
+		}
+	}
+	if furthest < 0 {
+		return Point{X: (a.X + b.X + c.X) / 3, Y: (a.Y + b.Y + c.Y) / 3}
+	}
+	return Point{X: (b.X + ring[furthest].X) / 2, Y: (b.Y + ring[furthest].Y) / 2}
+}
+
+// isSpike reports if the ring goes back at b along the edge from a
+func isSpike(a, b, c Point) bool {
+	return cross(a, b, c) == 0 && (a.X-b.X)*(c.X-b.X)+(a.Y-b.Y)*(c.Y-b.Y) > 0
+}
+
+func equalXY(a, b Point) bool {
+	return a.X == b.X && a.Y == b.Y
+}
+
+// closedPoly returns the points as closed ring like a shapefile ring
+func closedPoly(points []Point) *Poly {
+	poly := NewPoly()
+	for _, p := range points {
+		poly.Add(p)
+	}
+	if len(points) > 0 {
+		poly.Add(points[0])
+	}
+	return poly
+}
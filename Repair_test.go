@@ -0,0 +1,150 @@
+package Triangulate
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkRepaired checks that the polygons of Repair are valid shapefile polygons with the area want
+func checkRepaired(t *testing.T, name string, polygons []Polygon, want float64) {
+	for _, polygon := range polygons {
+		if problems := Validate(polygon); len(problems) > 0 {
+			t.Fatalf("%s: repaired polygon %v has problems %v", name, polygon.Outer.P, problems)
+		}
+		if !polygon.Outer.IsClockwise() {
+			t.Fatalf("%s: outer ring %v is counter-clockwise", name, polygon.Outer.P)
+		}
+		for _, hole := range polygon.Holes {
+			if hole.IsClockwise() {
+				t.Fatalf("%s: hole %v is clockwise", name, hole.P)
+			}
+		}
+	}
+	if got := polygonsArea(polygons); math.Abs(got-want) > 1e-9*math.Max(want, 1) {
+		t.Fatalf("%s: area %v, want %v", name, got, want)
+	}
+}
+
+func TestRepair(t *testing.T) {
+	ring := func(xy ...float64) *Poly {
+		var points []Point
+		for i := 0; i+1 < len(xy); i += 2 {
+			points = append(points, Point{X: xy[i], Y: xy[i+1]})
+		}
+		return closedPoly(points)
+	}
+	tests := []struct {
+		name     string
+		polygon  Polygon
+		area     float64
+		polygons int
+		problem  Problem
+	}{
+		{"bow-tie", Polygon{Outer: ring(0, 0, 2, 2, 2, 0, 0, 2)}, 2, 2, Problem{SelfIntersection, 0, Point{X: 1, Y: 1}}},
+		{"spike", Polygon{Outer: ring(0, 0, 0, 10, 15, 10, 10, 10, 10, 0)}, 100, 1, Problem{Spike, 0, Point{X: 15, Y: 10}}},
+		{"duplicate point", Polygon{Outer: ring(0, 0, 0, 10, 10, 10, 10, 10, 10, 0)}, 100, 1, Problem{DuplicatePoint, 0, Point{X: 10, Y: 10}}},
+		{"hole crossing the outer ring", Polygon{Outer: square(0, 0, 10), Holes: []*Poly{ring(5, 2, 15, 2, 15, 8, 5, 8)}},
+			100, 2, Problem{RingIntersection, 1, Point{X: 10, Y: 2}}},
+		{"figure eight", Polygon{Outer: ring(0, 0, 0, 2, 2, 2, 2, 4, 4, 4, 4, 2, 2, 2, 2, 0)}, 8, 2, Problem{SelfTouch, 0, Point{X: 2, Y: 2}}},
+		{"pinched hole", Polygon{Outer: ring(0, 0, 0, 4, 2, 4, 1, 2, 2, 1, 3, 2, 2, 4, 4, 4, 4, 0)}, 13, 1, Problem{SelfTouch, 0, Point{X: 2, Y: 4}}},
+	}
+	for _, test := range tests {
+		polygons, problems, err := Repair(test.polygon)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		checkRepaired(t, test.name, polygons, test.area)
+		if len(polygons) != test.polygons {
+			t.Errorf("%s: %d polygons, want %d", test.name, len(polygons), test.polygons)
+		}
+		found := false
+		for _, problem := range problems {
+			found = found || problem == test.problem
+		}
+		if !found {
+			t.Errorf("%s: problems %v, want %v", test.name, problems, test.problem)
+		}
+		if problems := Validate(test.polygon); len(problems) == 0 {
+			t.Errorf("%s: Validate finds no problems", test.name)
+		}
+	}
+}
+
+// TestRepairSampled compares the area of random rings on a small grid, which touch and overlap often, with the
+// even-odd membership of random points
+func TestRepairSampled(t *testing.T) {
+	r := rand.New(rand.NewSource(16))
+	for k := 0; k < 300; k++ {
+		var rings []*Poly
+		for n := 1 + r.Intn(3); n > 0; n-- {
+			var points []Point
+			for i := 3 + r.Intn(8); i > 0; i-- {
+				points = append(points, Point{X: float64(r.Intn(8)), Y: float64(r.Intn(8))})
+			}
+			rings = append(rings, closedPoly(points))
+		}
+		polygons, _, err := Repair(Polygon{Outer: rings[0], Holes: rings[1:]})
+		if err != nil {
+			t.Fatalf("%d: %v", k, err)
+		}
+		for _, polygon := range polygons {
+			if problems := Validate(polygon); len(problems) > 0 {
+				t.Fatalf("%d: repaired polygon %v has problems %v", k, polygon.Outer.P, problems)
+			}
+		}
+		for s := 0; s < 100; s++ {
+			p := Point{X: 8 * r.Float64(), Y: 8 * r.Float64()}
+			want := false
+			for _, ring := range rings {
+				if insideRing(p, ring.P) {
+					want = !want
+				}
+			}
+			if got := inPolygons(polygons, p); got != want {
+				t.Fatalf("%d: point %v in the repaired polygons %v, want %v", k, p, got, want)
+			}
+		}
+	}
+}
+
+func TestClockwiseBefore(t *testing.T) {
+	o := Point{X: 0.5, Y: -0.25}
+	// clockwise angle from the direction to a in (0, 2 pi], directions of small integer steps are far apart
+	angle := func(a, c Point) float64 {
+		d := math.Atan2(a.Y-o.Y, a.X-o.X) - math.Atan2(c.Y-o.Y, c.X-o.X)
+		for d <= 0 {
+			d += 2 * math.Pi
+		}
+		for d > 2*math.Pi {
+			d -= 2 * math.Pi
+		}
+		return d
+	}
+	var steps []Point
+	for x := -3; x <= 3; x++ {
+		for y := -3; y <= 3; y++ {
+			if x != 0 || y != 0 {
+				steps = append(steps, Point{X: o.X + float64(x), Y: o.Y + float64(y)})
+			}
+		}
+	}
+	for _, a := range steps {
+		for _, c := range steps {
+			for _, d := range steps {
+				want := angle(a, c) < angle(a, d)-1e-9
+				if got := clockwiseBefore(o, a, c, d); got != want {
+					t.Fatalf("a %v, c %v, d %v: before %v, want %v", a, c, d, got, want)
+				}
+			}
+		}
+	}
+	// directions that Atan2 rounds to the same angle
+	a, c, d := Point{X: 1}, Point{X: -1, Y: 1e-300}, Point{X: -1, Y: 2e-300}
+	if math.Atan2(c.Y, c.X) != math.Atan2(d.Y, d.X) {
+		t.Fatal("the angles differ, the test needs closer directions")
+	}
+	if want := exactOrient(Point{}, c, d) < 0; clockwiseBefore(Point{}, a, c, d) != want || clockwiseBefore(Point{}, a, d, c) == want {
+		t.Errorf("c %v, d %v: wrong order", c, d)
+	}
+}
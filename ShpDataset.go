@@ -0,0 +1,84 @@
+package shpReader
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Dataset is a shapefile with the attributes of its .dbf file and the coordinate system of its .prj file
+type Dataset struct {
+	Header   Header
+	Shapes   []ShapeData
+	Fields   []Field // nil without usable .dbf file
+	CRS      *CRS    // nil without usable .prj file
+	Skipped  []error // records that could not be decoded
+	Warnings []error // problems with the .dbf and .prj files
+}
+
+// Load reads a .shp file one record at a time together with the .dbf and .prj files next to it, when present.
+// All shapes are kept in memory, a file that does not fit is processed with NewShapeReader and ForEach.
+// Records that can not be decoded are skipped and reported in Skipped. Problems with the .dbf and .prj files do not
+// stop the load, they are reported in Warnings: a .dbf file that can not be read or joined is not used, values that
+// can not be parsed are kept as text, a .prj file that can not be parsed leaves CRS nil
+func Load(filename string) (dataset *Dataset, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	sr, err := NewShapeReader(file)
+	if err != nil {
+		return
+	}
+	sr.SkipInvalid = true
+	dataset = &Dataset{Header: sr.Header}
+	for {
+		shapeData, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		dataset.Shapes = append(dataset.Shapes, shapeData)
+	}
+	dataset.Skipped = sr.Skipped
+	if dbfName := SidecarName(filename, ".dbf"); dbfName != "" {
+		if err := dataset.readAttributes(dbfName); err != nil {
+			dataset.Warnings = append(dataset.Warnings, fmt.Errorf("%s: attributes not used: %w", dbfName, err))
+		}
+	}
+	if prjName := SidecarName(filename, ".prj"); prjName != "" {
+		crs, err := ReadPrj(prjName)
+		if err != nil {
+			dataset.Warnings = append(dataset.Warnings, fmt.Errorf("%s: coordinate system not used: %w", prjName, err))
+		} else {
+			dataset.CRS = crs
+		}
+	}
+	return dataset, nil
+}
+
+// readAttributes joins the records of the .dbf file to the shapes, the shapes keep no attributes when it fails
+func (dataset *Dataset) readAttributes(dbfName string) error {
+	bf, err := New(dbfName)
+	if err != nil {
+		return err
+	}
+	table, err := ReadDbf(&bf)
+	if err != nil {
+		return err
+	}
+	if err = JoinAttributes(dataset.Shapes, table); err != nil {
+		for i := range dataset.Shapes {
+			dataset.Shapes[i].Attributes = nil
+		}
+		return err
+	}
+	dataset.Fields = table.Fields
+	for _, invalid := range table.Invalid {
+		dataset.Warnings = append(dataset.Warnings, fmt.Errorf("%s: %w", dbfName, invalid))
+	}
+	return nil
+}
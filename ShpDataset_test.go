@@ -0,0 +1,85 @@
+package shpReader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePoints writes n point shapes with a NAME attribute and returns the name of the .shp file
+func writePoints(t *testing.T, n int) string {
+	var shapes []ShapeData
+	for i := 0; i < n; i++ {
+		shape, err := NewShapeData(POINT, [][][2]float64{{{float64(i), float64(i)}}}, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shape.Attributes = Attributes{"NAME": "point"}
+		shapes = append(shapes, shape)
+	}
+	filename := filepath.Join(t.TempDir(), "points.shp")
+	if err := WriteShapefile(filename, POINT, shapes, []Field{{Name: "NAME", Type: 'C', Length: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoad(t *testing.T) {
+	filename := writePoints(t, 3)
+	if err := os.WriteFile(strings.TrimSuffix(filename, ".shp")+".prj", []byte(utm32WKT), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dataset, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Shapes) != 3 || len(dataset.Warnings) != 0 || len(dataset.Fields) != 1 {
+		t.Fatalf("%d shapes, %d fields, warnings %v", len(dataset.Shapes), len(dataset.Fields), dataset.Warnings)
+	}
+	if dataset.CRS == nil || !dataset.CRS.Projected {
+		t.Errorf("coordinate system %v", dataset.CRS)
+	}
+	if dataset.Shapes[2].Attributes.String("NAME") != "point" {
+		t.Errorf("attributes %v", dataset.Shapes[2].Attributes)
+	}
+}
+
+func TestLoadWarnings(t *testing.T) {
+	for _, prj := range []string{"", `GEOGCRS["WGS 84",ELLIPSOID["WGS 84",6378137,298.257223563]]`} {
+		filename := writePoints(t, 3)
+		if err := os.WriteFile(strings.TrimSuffix(filename, ".shp")+".prj", []byte(prj), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		dataset, err := Load(filename)
+		if err != nil {
+			t.Fatalf("prj %q: %v", prj, err)
+		}
+		if len(dataset.Shapes) != 3 || dataset.CRS != nil || len(dataset.Warnings) != 1 || dataset.Fields == nil {
+			t.Errorf("prj %q: %d shapes, coordinate system %v, warnings %v", prj, len(dataset.Shapes), dataset.CRS, dataset.Warnings)
+		}
+	}
+	// a truncated .dbf file is not used
+	filename := writePoints(t, 3)
+	dbfName := strings.TrimSuffix(filename, ".shp") + ".dbf"
+	content, err := os.ReadFile(dbfName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(dbfName, content[:len(content)-5], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dataset, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Shapes) != 3 || dataset.Fields != nil || len(dataset.Warnings) != 1 || !errors.Is(dataset.Warnings[0], ErrTruncated) {
+		t.Fatalf("%d shapes, fields %v, warnings %v", len(dataset.Shapes), dataset.Fields, dataset.Warnings)
+	}
+	for _, shape := range dataset.Shapes {
+		if shape.Attributes != nil {
+			t.Errorf("record %d: attributes %v of an unused .dbf file", shape.RecordNum, shape.Attributes)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package shpReader
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors for invalid files, records with problems return them wrapped in a RecordError
+var (
+	ErrFileCode   = errors.New("Not a Shapefile") // file code is not 0x270A
+	ErrTruncated  = errors.New("truncated record")
+	ErrShapeType  = errors.New("bad shape type")
+	ErrPartIndex  = errors.New("part index out of range")
+	ErrFileLength = errors.New("record beyond file length")
+)
+
+// RecordError reports a problem in a record, Offset is the position in the file (in bytes) where the problem was found
+type RecordError struct {
+	Record int32
+	Offset int64
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d at byte %d: %v", e.Record, e.Offset, e.Err)
+}
+
+// Unwrap makes errors.Is(err, ErrTruncated) etc. work for a RecordError
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// validShapeType reports if shapeType is one of the known shape types
+func validShapeType(shapeType int32) bool {
+	switch shapeType {
+	case NULLSHAPE, POINT, POLYLINE, POLYGON, MULTIPOINT,
+		POINTZ, POLYLINEZ, POLYGONZ, MULTIPOINTZ,
+		POINTM, POLYLINEM, POLYGONM, MULTIPOINTM, MULTIPATCH:
+		return true
+	}
+	return false
+}
+
+// checkParts validates the part indexes: the first part starts at 0 and every part starts within the points
+// and not before the previous part
+func checkParts(bf *BinFileReader, parts []int32, numPoints int32) {
+	for i, part := range parts {
+		if (i == 0 && part != 0) || part < 0 || part >= numPoints || (i > 0 && part < parts[i-1]) {
+			bf.fail(ErrPartIndex)
+			return
+		}
+	}
+}
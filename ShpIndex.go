@@ -0,0 +1,195 @@
+package shpReader
+
+import (
+	"math"
+	"sort"
+)
+
+// nodeSize is the largest number of children of a node of the spatial index
+const nodeSize = 16
+
+// SpatialIndex is an R-tree over the bounding boxes of shapes, packed with Sort-Tile-Recursive
+// (S. Leutenegger, M. Lopez, J. Edgington, STR: A Simple and Efficient Algorithm for R-Tree Packing).
+// The tree is built once and not changed, it may be queried from many goroutines at the same time
+type SpatialIndex struct {
+	shapes []ShapeData
+	leaves []indexEntry   // the boxes of the shapes, child is the index of the shape
+	levels [][]indexEntry // the nodes from the level above the leaves up to the root, child is the first child
+}
+
+// indexEntry is a box in the tree with its children child up to end in the level below, or its shape in the leaves
+type indexEntry struct {
+	box        Box
+	child, end int
+}
+
+// NewSpatialIndex builds the index over the shapes, for example the Shapes of a Dataset. The shapes are not copied,
+// the results of the queries are indexes in shapes. Null shapes and shapes without points are left out
+func NewSpatialIndex(shapes []ShapeData) *SpatialIndex {
+	ix := &SpatialIndex{shapes: shapes}
+	for i, s := range shapes {
+		if s.ShapeType != NULLSHAPE && s.NumPoints > 0 {
+			ix.leaves = append(ix.leaves, indexEntry{box: s.Bounds(), child: i})
+		}
+	}
+	for entries := ix.leaves; len(entries) > 1; {
+		entries = packLevel(entries)
+		ix.levels = append(ix.levels, entries)
+	}
+	return ix
+}
+
+// packLevel sorts entries into tiles and returns the nodes of the level above, every node holds nodeSize consecutive
+// entries. The entries are sorted by the X of their centres into vertical slices, every slice by Y
+func packLevel(entries []indexEntry) []indexEntry {
+	n := len(entries)
+	nodes := (n + nodeSize - 1) / nodeSize
+	slice := nodeSize * int(math.Ceil(math.Sqrt(float64(nodes))))
+	centre := func(b Box, y bool) float64 {
+		if y {
+			return b.MinY + b.MaxY
+		}
+		return b.MinX + b.MaxX
+	}
+	sort.Slice(entries, func(i, j int) bool { return centre(entries[i].box, false) < centre(entries[j].box, false) })
+	for start := 0; start < n; start += slice {
+		tile := entries[start:minInt(start+slice, n)]
+		sort.Slice(tile, func(i, j int) bool { return centre(tile[i].box, true) < centre(tile[j].box, true) })
+	}
+	level := make([]indexEntry, 0, nodes)
+	for start := 0; start < n; start += nodeSize {
+		node := indexEntry{box: entries[start].box, child: start, end: minInt(start+nodeSize, n)}
+		for _, e := range entries[start+1 : node.end] {
+			node.box = node.box.Union(e.box)
+		}
+		level = append(level, node)
+	}
+	return level
+}
+
+// Len returns the number of shapes in the index
+func (ix *SpatialIndex) Len() int {
+	return len(ix.leaves)
+}
+
+// Search returns the indexes of the shapes whose bounding box intersects box
+func (ix *SpatialIndex) Search(box Box) (found []int) {
+	ix.SearchFunc(box, func(i int) bool {
+		found = append(found, i)
+		return true
+	})
+	return
+}
+
+// SearchFunc calls found with the index of every shape whose bounding box intersects box, until found returns false.
+// It does not allocate, for queries of many points
+func (ix *SpatialIndex) SearchFunc(box Box, found func(i int) bool) {
+	if len(ix.levels) == 0 {
+		for _, e := range ix.leaves {
+			if e.box.Intersects(box) && !found(e.child) {
+				return
+			}
+		}
+		return
+	}
+	ix.search(len(ix.levels)-1, 0, 1, box, found)
+}
+
+// search visits the entries from up to end of a level, the leaves below level 0, and reports if the search goes on
+func (ix *SpatialIndex) search(level, from, end int, box Box, found func(i int) bool) bool {
+	if level < 0 {
+		for _, e := range ix.leaves[from:end] {
+			if e.box.Intersects(box) && !found(e.child) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, e := range ix.levels[level][from:end] {
+		if e.box.Intersects(box) && !ix.search(level-1, e.child, e.end, box, found) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains returns the indexes of the polygon shapes that contain the point x, y, see ShapeData.Contains
+func (ix *SpatialIndex) Contains(x, y float64) (found []int) {
+	ix.SearchFunc(Box{x, y, x, y}, func(i int) bool {
+		if ix.shapes[i].Contains(x, y) {
+			found = append(found, i)
+		}
+		return true
+	})
+	return
+}
+
+// Locate returns the index of a polygon shape that contains the point x, y, false when the point is in no shape.
+// When shapes overlap or the point is on a shared border any of them is returned
+func (ix *SpatialIndex) Locate(x, y float64) (shape int, ok bool) {
+	ix.SearchFunc(Box{x, y, x, y}, func(i int) bool {
+		if ix.shapes[i].Contains(x, y) {
+			shape, ok = i, true
+		}
+		return !ok
+	})
+	return
+}
+
+// Bounds returns the bounding box of the shape as stored in the record
+func (s ShapeData) Bounds() Box {
+	return Box{s.Box0, s.Box1, s.Box2, s.Box3}
+}
+
+// Contains reports if the point x, y is inside a Polygon, PolygonZ or PolygonM shape, points on a ring count as
+// inside. The rings are tested together with the even-odd rule, so a point in a hole is outside and a point in an
+// island in the hole is inside again. Other shape types contain no points
+func (s ShapeData) Contains(x, y float64) bool {
+	switch s.ShapeType {
+	case POLYGON, POLYGONZ, POLYGONM:
+	default:
+		return false
+	}
+	if !s.Bounds().Contains(x, y) {
+		return false
+	}
+	inside := false
+	for _, ring := range s.Coordinates {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[j], ring[i]
+			// the cross product of a -> b and a -> p, 0 when p is on the line through a and b
+			cross := (b[0]-a[0])*(y-a[1]) - (b[1]-a[1])*(x-a[0])
+			if cross == 0 && x >= math.Min(a[0], b[0]) && x <= math.Max(a[0], b[0]) &&
+				y >= math.Min(a[1], b[1]) && y <= math.Max(a[1], b[1]) {
+				return true
+			}
+			// the edge crosses the ray from p to the right
+			if (a[1] > y) != (b[1] > y) && (cross > 0) == (b[1] > a[1]) {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Contains reports if the point x, y is inside the box or on its border
+func (b Box) Contains(x, y float64) bool {
+	return x >= b.MinX && x <= b.MaxX && y >= b.MinY && y <= b.MaxY
+}
+
+// Intersects reports if the boxes overlap or touch
+func (b Box) Intersects(o Box) bool {
+	return b.MinX <= o.MaxX && o.MinX <= b.MaxX && b.MinY <= o.MaxY && o.MinY <= b.MaxY
+}
+
+// Union returns the smallest box that contains both boxes
+func (b Box) Union(o Box) Box {
+	return Box{math.Min(b.MinX, o.MinX), math.Min(b.MinY, o.MinY), math.Max(b.MaxX, o.MaxX), math.Max(b.MaxY, o.MaxY)}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package shpReader
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// squareRing returns a closed square ring with its lower left corner at x, y, clockwise for an outer ring
+func squareRing(x, y, size float64, clockwise bool) [][2]float64 {
+	if clockwise {
+		return [][2]float64{{x, y}, {x, y + size}, {x + size, y + size}, {x + size, y}, {x, y}}
+	}
+	return [][2]float64{{x, y}, {x + size, y}, {x + size, y + size}, {x, y + size}, {x, y}}
+}
+
+// randomSquares returns n squares that may overlap, every third square has a hole in its middle half, and null shapes
+func randomSquares(t *testing.T, r *rand.Rand, n int) (shapes []ShapeData) {
+	for i := 0; i < n; i++ {
+		x, y, size := r.Float64()*1000, r.Float64()*1000, 1+r.Float64()*20
+		rings := [][][2]float64{squareRing(x, y, size, true)}
+		if i%3 == 0 {
+			rings = append(rings, squareRing(x+size/4, y+size/4, size/2, false))
+		}
+		shape, err := NewShapeData(POLYGON, rings, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shapes = append(shapes, shape)
+		if i%500 == 0 {
+			shapes = append(shapes, ShapeData{ShapeType: NULLSHAPE})
+		}
+	}
+	return
+}
+
+func TestSpatialIndexContains(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	shapes := randomSquares(t, r, 3000)
+	ix := NewSpatialIndex(shapes)
+	if ix.Len() != 3000 {
+		t.Fatalf("%d shapes in the index, want 3000", ix.Len())
+	}
+	hits := 0
+	for k := 0; k < 5000; k++ {
+		x, y := r.Float64()*1000, r.Float64()*1000
+		var want []int
+		for i, s := range shapes {
+			if s.Contains(x, y) {
+				want = append(want, i)
+			}
+		}
+		got := ix.Contains(x, y)
+		sort.Ints(got)
+		if len(got) != len(want) {
+			t.Fatalf("%g, %g: Contains %v, want %v", x, y, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("%g, %g: Contains %v, want %v", x, y, got, want)
+			}
+		}
+		shape, ok := ix.Locate(x, y)
+		if ok != (len(want) > 0) || (ok && !shapes[shape].Contains(x, y)) {
+			t.Fatalf("%g, %g: Locate %d %v, want one of %v", x, y, shape, ok, want)
+		}
+		hits += len(want)
+	}
+	if hits == 0 {
+		t.Error("no point is in a square")
+	}
+}
+
+func TestShapeDataContains(t *testing.T) {
+	shape, err := NewShapeData(POLYGON, [][][2]float64{squareRing(0, 0, 8, true), squareRing(2, 2, 4, false),
+		squareRing(3, 3, 2, true)}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		x, y float64
+		want bool
+	}{
+		{1, 1, true},    // inside
+		{0, 0, true},    // corner
+		{0, 4, true},    // edge
+		{2.5, 4, false}, // hole
+		{2, 4, true},    // edge of the hole
+		{4, 4, true},    // island in the hole
+		{-1, 4, false},  // left
+		{4, 8.5, false}, // above
+	}
+	for _, test := range tests {
+		if got := shape.Contains(test.x, test.y); got != test.want {
+			t.Errorf("%g, %g: %v, want %v", test.x, test.y, got, test.want)
+		}
+	}
+	line, err := NewShapeData(POLYLINE, [][][2]float64{{{0, 0}, {8, 8}}}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix := NewSpatialIndex([]ShapeData{line, shape})
+	if got := ix.Contains(4, 4); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Contains %v, want [1], a line contains no points", got)
+	}
+	if got := ix.Search(Box{-1, -1, 0, 0}); len(got) != 2 {
+		t.Errorf("Search %v, want both shapes touching the box", got)
+	}
+	if _, ok := ix.Locate(9, 9); ok {
+		t.Error("Locate found a shape outside all shapes")
+	}
+}
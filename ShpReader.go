@@ -6,8 +6,8 @@ package shpReader
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 )
@@ -46,7 +46,26 @@ const (
 	POLYLINEM   = 23
 	POLYGONM    = 25
 	MULTIPOINTM = 28
-	MULTIPATH   = 31
+	MULTIPATCH  = 31
+	MULTIPATH   = MULTIPATCH // original name, kept for compatibility
+)
+
+/*
+Value 	Part type 	Description
+   0 	Triangle strip 	every vertex after the first 2 forms a triangle with the 2 previous vertices
+   1 	Triangle fan 	every vertex after the first 2 forms a triangle with the first and the previous vertex
+   2 	Outer ring 	outer ring of a polygon
+   3 	Inner ring 	hole of the preceding outer ring
+   4 	First ring 	first ring of a polygon of unspecified type
+   5 	Ring 	        ring of a polygon of unspecified type
+*/
+const (
+	TRIANGLESTRIP = 0
+	TRIANGLEFAN   = 1
+	OUTERRING     = 2
+	INNERRING     = 3
+	FIRSTRING     = 4
+	RING          = 5
 )
 
 type Header struct {
@@ -86,35 +105,41 @@ type Header struct {
 }
 
 func (h Header) String() string {
-	return fmt.Sprintf("filecode   :%#X\nfilelength :%v\nversion    :%v\nshape      :%d\nminX :%2.2f\nmaxX :%2.2f\nminY :%2.2f\nmaxY :%2.2f\n",
+	return fmt.Sprintf("filecode   :%#X\nfilelength :%v\nversion    :%v\nshape      :%d\nminX :%2.2f\nmaxX :%2.2f\nminY :%2.2f\nmaxY :%2.2f\nminZ :%2.2f\nmaxZ :%2.2f\nminM :%2.2f\nmaxM :%2.2f\n",
 		h.FileCode, h.FileLength, h.Version, h.ShapeType,
 		h.MinX, h.MaxX,
-		h.MinY, h.MaxY)
+		h.MinY, h.MaxY,
+		h.MinZ, h.MaxZ,
+		h.MinM, h.MaxM)
 }
 
 func readHeader(bf *BinFileReader) (header Header, err error) {
 	content := bf.ReadByte(100)
+	if bf.err != nil {
+		return header, fmt.Errorf("header: %w", bf.err)
+	}
 	buffer := bytes.NewBuffer(content)
 	header = Header{}
 	if err = binary.Read(buffer, binary.LittleEndian, &header); err != nil {
 		return
 	}
 	header.FileCode = swapEncodingUint32(header.FileCode)
+	header.FileLength = int32(swapEncodingUint32(uint32(header.FileLength))) // big endian as well
 	if header.FileCode != 0X270A {
-		err = errors.New("Not a Shapefile")
+		err = ErrFileCode
+	} else if !validShapeType(header.ShapeType) {
+		err = fmt.Errorf("header: %w %d", ErrShapeType, header.ShapeType)
+	} else if header.FileLength < 50 {
+		err = fmt.Errorf("header: file length %d words", header.FileLength)
 	}
 	return
 }
 
-func writeHeader(header *Header) (err error) {
-	buf := new(bytes.Buffer)
+// writeHeader writes the header in the layout of the file, FileCode and FileLength are big endian
+func writeHeader(w io.Writer, header Header) error {
 	header.FileCode = swapEncodingUint32(header.FileCode)
-	if err = binary.Write(buf, binary.LittleEndian, header); err != nil {
-		return
-	}
-	// raw data -> file fmt.Println(buf)
-	fmt.Printf("% x\n", buf.Bytes())
-	return
+	header.FileLength = int32(swapEncodingUint32(uint32(header.FileLength)))
+	return binary.Write(w, binary.LittleEndian, header)
 }
 
 func swapEncodingUint16(val uint16) uint16 { //BigEndian <->LittleEndian
@@ -127,6 +152,13 @@ func swapEncodingUint32(val uint32) uint32 { //BigEndian <->LittleEndian
 		((val << 24) & 0xff000000) // move byte 0 to byte 3
 }
 
+// ShapeData holds a record in a generic form for all shape types, Shape holds the decoded record itself.
+// PartCount holds the index after the last point of each part.
+// For (Multi)Point shapes NumParts is 0 and Coordinates holds a single list with all points
+// Z and M hold the Z and M value per point with the same layout as Coordinates,
+// they are nil if the shape type has no Z or the optional M values are absent
+// PartTypes holds the part type of each part for MultiPatch shapes
+// Attributes holds the values of the matching .dbf record, see JoinAttributes
 type ShapeData struct {
 	RecordNum     int32
 	ContentLength int32
@@ -139,13 +171,118 @@ type ShapeData struct {
 	NumPoints     int32 //Big endian
 	PartCount     []int
 	Coordinates   [][][2]float64
+	Z             [][]float64
+	M             [][]float64
+	PartTypes     []int32
+	Shape         Shape
+	Attributes    Attributes
+}
+
+// newShapeData fills the generic fields of ShapeData from a decoded shape
+func newShapeData(recordNum, contentLength int32, shape Shape) (shapeData ShapeData) {
+	shapeData.RecordNum = recordNum
+	shapeData.ContentLength = contentLength
+	shapeData.ShapeType = shape.Type()
+	shapeData.Shape = shape
+	var box Box
+	var parts []int32
+	var points []Point
+	var z, m []float64
+	switch s := shape.(type) {
+	case Null:
+		return
+	case Point:
+		box, points = Box{s.X, s.Y, s.X, s.Y}, []Point{s}
+	case PointM:
+		box, points, m = Box{s.X, s.Y, s.X, s.Y}, []Point{{s.X, s.Y}}, []float64{s.M}
+	case PointZ:
+		box, points, z = Box{s.X, s.Y, s.X, s.Y}, []Point{{s.X, s.Y}}, []float64{s.Z}
+		if !IsNoData(s.M) {
+			m = []float64{s.M}
+		}
+	case MultiPoint:
+		box, points = s.Box, s.Points
+	case MultiPointM:
+		box, points, m = s.Box, s.Points, s.M
+	case MultiPointZ:
+		box, points, z, m = s.Box, s.Points, s.Z, s.M
+	case PolyLine:
+		box, parts, points = s.Box, s.Parts, s.Points
+	case PolyLineM:
+		box, parts, points, m = s.Box, s.Parts, s.Points, s.M
+	case PolyLineZ:
+		box, parts, points, z, m = s.Box, s.Parts, s.Points, s.Z, s.M
+	case Polygon:
+		box, parts, points = s.Box, s.Parts, s.Points
+	case PolygonM:
+		box, parts, points, m = s.Box, s.Parts, s.Points, s.M
+	case PolygonZ:
+		box, parts, points, z, m = s.Box, s.Parts, s.Points, s.Z, s.M
+	case MultiPatch:
+		box, parts, points, z, m = s.Box, s.Parts, s.Points, s.Z, s.M
+		shapeData.PartTypes = s.PartTypes
+	}
+	shapeData.Box0, shapeData.Box1, shapeData.Box2, shapeData.Box3 = box.MinX, box.MinY, box.MaxX, box.MaxY
+	shapeData.NumParts = int32(len(parts))
+	shapeData.NumPoints = int32(len(points))
+	if parts == nil { // points only
+		coordinates := make([][2]float64, len(points))
+		for i, p := range points {
+			coordinates[i] = [2]float64{p.X, p.Y}
+		}
+		shapeData.Coordinates = [][][2]float64{coordinates}
+		if z != nil {
+			shapeData.Z = [][]float64{z}
+		}
+		if m != nil {
+			shapeData.M = [][]float64{m}
+		}
+		return
+	}
+	shapeData.PartCount = make([]int, len(parts))
+	for i := range parts {
+		if i < len(parts)-1 {
+			shapeData.PartCount[i] = int(parts[i+1])
+		} else {
+			shapeData.PartCount[i] = len(points)
+		}
+		var coordinates [][2]float64
+		for j := int(parts[i]); j < shapeData.PartCount[i]; j++ {
+			coordinates = append(coordinates, [2]float64{points[j].X, points[j].Y})
+		}
+		shapeData.Coordinates = append(shapeData.Coordinates, coordinates)
+		if z != nil {
+			shapeData.Z = append(shapeData.Z, z[parts[i]:shapeData.PartCount[i]])
+		}
+		if m != nil {
+			shapeData.M = append(shapeData.M, m[parts[i]:shapeData.PartCount[i]])
+		}
+	}
+	return
+}
+
+// HasZ reports if the shape holds Z values
+func (s ShapeData) HasZ() bool {
+	return s.Z != nil
+}
+
+// HasM reports if the shape holds M values
+func (s ShapeData) HasM() bool {
+	return s.M != nil
 }
 
 func (s ShapeData) String() string {
 	outStr := "Coordinates:\n    X    ,  Y\n"
-	for _, p := range s.Coordinates {
-		for _, c := range p {
-			outStr += fmt.Sprintf("%2.2f,%2.2f\n", c[0], c[1])
+	for i, p := range s.Coordinates {
+		for j, c := range p {
+			outStr += fmt.Sprintf("%2.2f,%2.2f", c[0], c[1])
+			if s.HasZ() {
+				outStr += fmt.Sprintf(" Z:%2.2f", s.Z[i][j])
+			}
+			if s.HasM() {
+				outStr += fmt.Sprintf(" M:%2.2f", s.M[i][j])
+			}
+			outStr += "\n"
 		}
 		outStr += ",\n"
 	}
@@ -153,13 +290,82 @@ func (s ShapeData) String() string {
 		s.RecordNum, s.ContentLength, s.ShapeType, s.Box0, s.Box1, s.Box2, s.Box3, s.NumParts, s.NumPoints, s.PartCount, outStr)
 }
 
-func ReadPolygons(bf *BinFileReader) (header Header, data []ShapeData, err error) {
+// ReadShapes reads the header and all records of a shapefile, regardless of their shape type
+func ReadShapes(bf *BinFileReader) (header Header, data []ShapeData, err error) {
 	/*
 		   ***vvv Description of Main File Record Headers  vvv***
 		    Byte Position Field          Value          Type    Order
 		    Byte 0        Record Number  Record Number  Integer Big
 		    Byte 4        Content Length Content Length Integer Big
 		    ***^^^ Description of Main File Record Headers  ^^^***
+		    ***vvv Record Contents vvv***
+		    Position Field          Value     Type     Number    Order
+		    Byte 0   Shape Type     ShapeType Integer  1         Little
+		    Byte 4   Shape content, see readShape
+		    ***^^^ Record Contents ^^^***
+		    Content Length is in 16-bit words and includes the shape type
+	*/
+	header, err = readHeader(bf)
+	if err != nil {
+		return
+	}
+	// ignore anything after the file length of the header, a file that ends before it is truncated
+	bf.length = int(header.FileLength) * 2
+	for !bf.EOF() {
+		if !bf.available(8) {
+			return header, data, &RecordError{Record: int32(len(data) + 1), Offset: int64(bf.pos), Err: ErrTruncated}
+		}
+		shapeData, err := readRecord(bf, 0, header.ShapeType)
+		if err != nil {
+			return header, data, err
+		}
+		data = append(data, shapeData)
+	}
+	return
+}
+
+// readRecord reads the record header and the record at the current position.
+// offset is the position of the content of bf in the file, it is used in errors only.
+// fileShapeType is the shape type of the header, all records must have this shape type or be Null shapes.
+// Problems with the record are returned as a RecordError
+func readRecord(bf *BinFileReader, offset int64, fileShapeType int32) (shapeData ShapeData, err error) {
+	recordNum := bf.ReadIntLittle()
+	contentLength := bf.ReadIntLittle()
+	recordError := func(err error, pos int) error {
+		return &RecordError{Record: recordNum, Offset: offset + int64(pos), Err: err}
+	}
+	if bf.err != nil {
+		return shapeData, recordError(bf.err, bf.errPos)
+	}
+	end := bf.pos + int(contentLength)*2
+	if contentLength < 2 || end > bf.length { // the content holds at least the shape type
+		return shapeData, recordError(ErrFileLength, bf.pos-4)
+	}
+	if end > len(bf.b) {
+		return shapeData, recordError(ErrTruncated, bf.pos)
+	}
+	start := bf.pos
+	shapeType := bf.ReadIntBig()
+	if !validShapeType(shapeType) || (shapeType != NULLSHAPE && shapeType != fileShapeType) {
+		return shapeData, recordError(fmt.Errorf("%w %d in a file of shape type %d", ErrShapeType, shapeType, fileShapeType), start)
+	}
+	length := bf.length
+	bf.length = end // never read into the next record
+	shape, err := readShape(bf, shapeType, end)
+	bf.length = length
+	if err != nil {
+		return shapeData, recordError(err, start)
+	}
+	if bf.err != nil {
+		return shapeData, recordError(bf.err, bf.errPos)
+	}
+	bf.pos = end // skip any padding after the shape content
+	return newShapeData(recordNum, contentLength, shape), nil
+}
+
+// ReadPolygons reads all records and returns only the Polygon, PolygonZ and PolygonM records
+func ReadPolygons(bf *BinFileReader) (header Header, data []ShapeData, err error) {
+	/*
 			   Polygon
 		    {
 		    Float[4] Box // Bounding Box
@@ -180,60 +386,38 @@ func ReadPolygons(bf *BinFileReader) (header Header, data []ShapeData, err error
 		    note X = 44 + 4 * numParts
 		    ***^^^ Polygon Record Contents ^^^***
 	*/
-
-	//shapeNo := 0
-	var shapesData []ShapeData
-	head, err := readHeader(bf)
-	if err != nil {
-		return
-	}
-	for !bf.EOF() {
-		shapeData := ShapeData{} //shapeNo++
-		shapeData.RecordNum = bf.ReadIntLittle()
-		shapeData.ContentLength = bf.ReadIntLittle()
-		shapeData.ShapeType = bf.ReadIntBig()
-		shapeData.Box0 = bf.ReadFloatLittle()
-		shapeData.Box1 = bf.ReadFloatLittle()
-		shapeData.Box2 = bf.ReadFloatLittle()
-		shapeData.Box3 = bf.ReadFloatLittle()
-		shapeData.NumParts = bf.ReadIntBig()
-		shapeData.NumPoints = bf.ReadIntBig()
-		shapeData.PartCount = make([]int, shapeData.NumParts)
-		for i := 0; i < int(shapeData.NumParts); i++ {
-			shapeData.PartCount[i] = int(bf.ReadIntBig())
+	header, shapes, err := ReadShapes(bf)
+	for _, shape := range shapes {
+		switch shape.ShapeType {
+		case POLYGON, POLYGONZ, POLYGONM:
+			data = append(data, shape)
 		}
-		count := 0
-		for count = 0; count < len(shapeData.PartCount)-1; count++ {
-			shapeData.PartCount[count] = shapeData.PartCount[count+1]
-		}
-		shapeData.PartCount[count] = int(shapeData.NumPoints)
-		j := 0
-		for i := 0; i < int(shapeData.NumParts); i++ {
-			var points [][2]float64
-			for ; j < shapeData.PartCount[i]; j++ {
-				x := bf.ReadFloatLittle()
-				y := bf.ReadFloatLittle()
-				points = append(points, [2]float64{x, y})
-			}
-			shapeData.Coordinates = append(shapeData.Coordinates, points)
-		}
-		shapesData = append(shapesData, shapeData)
 	}
-	return head, shapesData, err
+	return
 }
 
+// BinFileReader reads values from content in memory. Reading beyond the end does not panic,
+// zero values are returned and Err reports ErrTruncated; the first error is kept.
 type BinFileReader struct {
 	b      []byte
 	pos    int
 	length int
+	err    error
+	errPos int
 }
 
+// New reads the complete file in memory, large shapefiles are better read with NewShapeReader and Next or ForEach
 func New(filename string) (bf BinFileReader, err error) {
 	content, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return
 	}
 	//fmt.Println(len(content))
+	return NewFromBytes(content), nil
+}
+
+// NewFromBytes creates a reader on content that is already in memory
+func NewFromBytes(content []byte) (bf BinFileReader) {
 	bf.b = content
 	bf.pos = 0
 	bf.length = len(bf.b)
@@ -244,7 +428,36 @@ func (bf *BinFileReader) EOF() bool {
 	return bf.pos >= bf.length
 }
 
+// Err returns the first error that occurred while reading
+func (bf *BinFileReader) Err() error {
+	return bf.err
+}
+
+// fail keeps the first error and the position where it occurred
+func (bf *BinFileReader) fail(err error) {
+	if bf.err == nil {
+		bf.err = err
+		bf.errPos = bf.pos
+	}
+}
+
+// available checks if nBytes can be read from the current position, if not ErrTruncated is kept.
+// length may be beyond the content when the content is shorter than the header states
+func (bf *BinFileReader) available(nBytes int) bool {
+	if nBytes < 0 || bf.pos+nBytes > bf.length || bf.pos+nBytes > len(bf.b) {
+		bf.fail(ErrTruncated)
+		return false
+	}
+	return true
+}
+
 func (bf *BinFileReader) ReadByte(nBytes int) []byte {
+	if !bf.available(nBytes) {
+		if nBytes < 0 {
+			nBytes = 0
+		}
+		return make([]byte, nBytes)
+	}
 	buf := make([]byte, nBytes)
 	for i := 0; i < nBytes; i++ {
 		buf[i] = bf.b[bf.pos]
@@ -254,6 +467,9 @@ func (bf *BinFileReader) ReadByte(nBytes int) []byte {
 }
 
 func (bf *BinFileReader) ReadIntBig() int32 {
+	if !bf.available(4) {
+		return 0
+	}
 	buf := make([]byte, 4)
 	for i := 0; i < 4; i++ {
 		buf[i] = bf.b[bf.pos]
@@ -263,6 +479,9 @@ func (bf *BinFileReader) ReadIntBig() int32 {
 }
 
 func (bf *BinFileReader) ReadIntLittle() int32 {
+	if !bf.available(4) {
+		return 0
+	}
 	buf := make([]byte, 4)
 	for i := 0; i < 4; i++ {
 		buf[i] = bf.b[bf.pos]
@@ -272,6 +491,9 @@ func (bf *BinFileReader) ReadIntLittle() int32 {
 }
 
 func (bf *BinFileReader) ReadFloatBig() float64 {
+	if !bf.available(8) {
+		return 0
+	}
 	buf := make([]byte, 8)
 	for i := 0; i < 8; i++ {
 		buf[i] = bf.b[bf.pos]
@@ -283,6 +505,9 @@ func (bf *BinFileReader) ReadFloatBig() float64 {
 }
 
 func (bf *BinFileReader) ReadFloatLittle() float64 {
+	if !bf.available(8) {
+		return 0
+	}
 	buf := make([]byte, 8)
 	for i := 0; i < 8; i++ {
 		buf[i] = bf.b[bf.pos]
@@ -302,7 +527,7 @@ func (bf *BinFileReader) ReadFloatLittle() float64 {
 //	if err != nil {
 //		fmt.Println(err)
 //	}
-//	if err = writeHeader(&head); err != nil {
+//	if err = writeHeader(os.Stdout, head); err != nil {
 //		fmt.Println(err)
 //	}
 //	fmt.Println(head)
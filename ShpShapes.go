@@ -0,0 +1,298 @@
+package shpReader
+
+import (
+	"fmt"
+)
+
+// Shape is the decoded content of a single record, the concrete type depends on the shape type of the record:
+// Null, Point, PolyLine, Polygon, MultiPoint, PointZ, PolyLineZ, PolygonZ, MultiPointZ,
+// PointM, PolyLineM, PolygonM, MultiPointM or MultiPatch
+type Shape interface {
+	Type() int32
+}
+
+// Box is a bounding box, stored in the file as Xmin, Ymin, Xmax, Ymax
+type Box struct {
+	MinX float64
+	MinY float64
+	MaxX float64
+	MaxY float64
+}
+
+// Range is a Z or M range, stored in the file as min, max
+type Range struct {
+	Min float64
+	Max float64
+}
+
+// NoData is used for M values that are not present in the file,
+// any M value below -1e38 is considered "no data" by the shapefile specification
+const NoData = -1e39
+
+// IsNoData reports if an M value is to be considered as "no data"
+func IsNoData(m float64) bool {
+	return m < -1e38
+}
+
+// Null shape, has no content apart from the shape type
+type Null struct{}
+
+// Point, X, Y
+type Point struct {
+	X float64
+	Y float64
+}
+
+// MultiPoint, MBR, Number of points, Points
+type MultiPoint struct {
+	Box       Box
+	NumPoints int32
+	Points    []Point
+}
+
+// PolyLine, MBR, Number of parts, Number of points, Parts, Points
+// Parts holds the index of the first point of each part
+type PolyLine struct {
+	Box       Box
+	NumParts  int32
+	NumPoints int32
+	Parts     []int32
+	Points    []Point
+}
+
+// Polygon has the same layout as PolyLine, parts are closed rings
+type Polygon PolyLine
+
+// PointM, X, Y, M
+type PointM struct {
+	X float64
+	Y float64
+	M float64
+}
+
+// PointZ, X, Y, Z, M (M is optional in some writers and then set to NoData)
+type PointZ struct {
+	X float64
+	Y float64
+	Z float64
+	M float64
+}
+
+// MultiPointM, MultiPoint + optional M range and M array
+type MultiPointM struct {
+	MultiPoint
+	MRange Range
+	M      []float64
+}
+
+// MultiPointZ, MultiPoint + Z range, Z array + optional M range and M array
+type MultiPointZ struct {
+	MultiPoint
+	ZRange Range
+	Z      []float64
+	MRange Range
+	M      []float64
+}
+
+// PolyLineM, PolyLine + optional M range and M array
+type PolyLineM struct {
+	PolyLine
+	MRange Range
+	M      []float64
+}
+
+// PolyLineZ, PolyLine + Z range, Z array + optional M range and M array
+type PolyLineZ struct {
+	PolyLine
+	ZRange Range
+	Z      []float64
+	MRange Range
+	M      []float64
+}
+
+// PolygonM, Polygon + optional M range and M array
+type PolygonM struct {
+	Polygon
+	MRange Range
+	M      []float64
+}
+
+// PolygonZ, Polygon + Z range, Z array + optional M range and M array
+type PolygonZ struct {
+	Polygon
+	ZRange Range
+	Z      []float64
+	MRange Range
+	M      []float64
+}
+
+// MultiPatch, MBR, Number of parts, Number of points, Parts, Part types, Points, Z range, Z array
+// + optional M range and M array
+type MultiPatch struct {
+	Box       Box
+	NumParts  int32
+	NumPoints int32
+	Parts     []int32
+	PartTypes []int32
+	Points    []Point
+	ZRange    Range
+	Z         []float64
+	MRange    Range
+	M         []float64
+}
+
+func (Null) Type() int32        { return NULLSHAPE }
+func (Point) Type() int32       { return POINT }
+func (PolyLine) Type() int32    { return POLYLINE }
+func (Polygon) Type() int32     { return POLYGON }
+func (MultiPoint) Type() int32  { return MULTIPOINT }
+func (PointZ) Type() int32      { return POINTZ }
+func (PolyLineZ) Type() int32   { return POLYLINEZ }
+func (PolygonZ) Type() int32    { return POLYGONZ }
+func (MultiPointZ) Type() int32 { return MULTIPOINTZ }
+func (PointM) Type() int32      { return POINTM }
+func (PolyLineM) Type() int32   { return POLYLINEM }
+func (PolygonM) Type() int32    { return POLYGONM }
+func (MultiPointM) Type() int32 { return MULTIPOINTM }
+func (MultiPatch) Type() int32  { return MULTIPATCH }
+
+// readShape decodes the content of a record of the given shape type, the shape type itself is already read.
+// end is the position in bf directly after the record, it is used to detect the optional M values
+func readShape(bf *BinFileReader, shapeType int32, end int) (shape Shape, err error) {
+	switch shapeType {
+	case NULLSHAPE:
+		shape = Null{}
+	case POINT:
+		shape = Point{X: bf.ReadFloatLittle(), Y: bf.ReadFloatLittle()}
+	case POINTM:
+		shape = PointM{X: bf.ReadFloatLittle(), Y: bf.ReadFloatLittle(), M: bf.ReadFloatLittle()}
+	case POINTZ:
+		p := PointZ{X: bf.ReadFloatLittle(), Y: bf.ReadFloatLittle(), Z: bf.ReadFloatLittle(), M: NoData}
+		if bf.pos < end {
+			p.M = bf.ReadFloatLittle()
+		}
+		shape = p
+	case MULTIPOINT:
+		shape = readMultiPoint(bf)
+	case MULTIPOINTM:
+		mp := MultiPointM{MultiPoint: readMultiPoint(bf)}
+		if bf.pos < end {
+			mp.MRange, mp.M = readMeasures(bf, mp.NumPoints)
+		}
+		shape = mp
+	case MULTIPOINTZ:
+		mp := MultiPointZ{MultiPoint: readMultiPoint(bf)}
+		mp.ZRange, mp.Z = readMeasures(bf, mp.NumPoints)
+		if bf.pos < end {
+			mp.MRange, mp.M = readMeasures(bf, mp.NumPoints)
+		}
+		shape = mp
+	case POLYLINE:
+		shape = readPolyLine(bf)
+	case POLYLINEM:
+		pl := PolyLineM{PolyLine: readPolyLine(bf)}
+		if bf.pos < end {
+			pl.MRange, pl.M = readMeasures(bf, pl.NumPoints)
+		}
+		shape = pl
+	case POLYLINEZ:
+		pl := PolyLineZ{PolyLine: readPolyLine(bf)}
+		pl.ZRange, pl.Z = readMeasures(bf, pl.NumPoints)
+		if bf.pos < end {
+			pl.MRange, pl.M = readMeasures(bf, pl.NumPoints)
+		}
+		shape = pl
+	case POLYGON:
+		shape = Polygon(readPolyLine(bf))
+	case POLYGONM:
+		pg := PolygonM{Polygon: Polygon(readPolyLine(bf))}
+		if bf.pos < end {
+			pg.MRange, pg.M = readMeasures(bf, pg.NumPoints)
+		}
+		shape = pg
+	case POLYGONZ:
+		pg := PolygonZ{Polygon: Polygon(readPolyLine(bf))}
+		pg.ZRange, pg.Z = readMeasures(bf, pg.NumPoints)
+		if bf.pos < end {
+			pg.MRange, pg.M = readMeasures(bf, pg.NumPoints)
+		}
+		shape = pg
+	case MULTIPATCH:
+		mp := MultiPatch{}
+		mp.Box = readBox(bf)
+		mp.NumParts = bf.ReadIntBig()
+		mp.NumPoints = bf.ReadIntBig()
+		mp.Parts = readInts(bf, mp.NumParts)
+		mp.PartTypes = readInts(bf, mp.NumParts)
+		mp.Points = readPoints(bf, mp.NumPoints)
+		checkParts(bf, mp.Parts, mp.NumPoints)
+		mp.ZRange, mp.Z = readMeasures(bf, mp.NumPoints)
+		if bf.pos < end {
+			mp.MRange, mp.M = readMeasures(bf, mp.NumPoints)
+		}
+		shape = mp
+	default:
+		err = fmt.Errorf("unknown shape type %d", shapeType)
+	}
+	return
+}
+
+func readBox(bf *BinFileReader) Box {
+	return Box{bf.ReadFloatLittle(), bf.ReadFloatLittle(), bf.ReadFloatLittle(), bf.ReadFloatLittle()}
+}
+
+// readInts, readPoints and readMeasures check the length first,
+// a corrupt count must not lead to a huge allocation
+func readInts(bf *BinFileReader, n int32) []int32 {
+	if !bf.available(int(n) * 4) {
+		return nil
+	}
+	ints := make([]int32, n)
+	for i := range ints {
+		ints[i] = bf.ReadIntBig()
+	}
+	return ints
+}
+
+func readPoints(bf *BinFileReader, n int32) []Point {
+	if !bf.available(int(n) * 16) {
+		return nil
+	}
+	points := make([]Point, n)
+	for i := range points {
+		points[i].X = bf.ReadFloatLittle()
+		points[i].Y = bf.ReadFloatLittle()
+	}
+	return points
+}
+
+// readMeasures reads a Z or M range followed by n values
+func readMeasures(bf *BinFileReader, n int32) (r Range, values []float64) {
+	r.Min = bf.ReadFloatLittle()
+	r.Max = bf.ReadFloatLittle()
+	if !bf.available(int(n) * 8) {
+		return
+	}
+	values = make([]float64, n)
+	for i := range values {
+		values[i] = bf.ReadFloatLittle()
+	}
+	return
+}
+
+func readMultiPoint(bf *BinFileReader) (mp MultiPoint) {
+	mp.Box = readBox(bf)
+	mp.NumPoints = bf.ReadIntBig()
+	mp.Points = readPoints(bf, mp.NumPoints)
+	return
+}
+
+func readPolyLine(bf *BinFileReader) (pl PolyLine) {
+	pl.Box = readBox(bf)
+	pl.NumParts = bf.ReadIntBig()
+	pl.NumPoints = bf.ReadIntBig()
+	pl.Parts = readInts(bf, pl.NumParts)
+	pl.Points = readPoints(bf, pl.NumPoints)
+	checkParts(bf, pl.Parts, pl.NumPoints)
+	return
+}
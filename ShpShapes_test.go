@@ -0,0 +1,144 @@
+package shpReader
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readBack writes the shapes to a shapefile of shapeType and reads them with ReadShapes
+func readBack(t *testing.T, shapeType int32, shapes ...ShapeData) (Header, []ShapeData) {
+	filename := filepath.Join(t.TempDir(), "shapes.shp")
+	if err := WriteShapefile(filename, shapeType, shapes, nil); err != nil {
+		t.Fatal(err)
+	}
+	bf, err := New(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, data, err := ReadShapes(&bf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header, data
+}
+
+func TestReadShapeTypes(t *testing.T) {
+	point := [][][2]float64{{{3, 4}}}
+	points := [][][2]float64{{{0, 0}, {1, 2}, {3, 4}}}
+	lines := [][][2]float64{{{0, 0}, {1, 1}, {2, 0}}, {{5, 5}, {6, 6}}}
+	rings := [][][2]float64{{{0, 0}, {0, 4}, {4, 4}, {4, 0}, {0, 0}}, {{1, 1}, {2, 1}, {2, 2}, {1, 1}}}
+	tests := []struct {
+		shapeType   int32
+		coordinates [][][2]float64
+		z, m        [][]float64
+		partTypes   []int32
+		want        Shape
+	}{
+		{POINT, point, nil, nil, nil, Point{}},
+		{POINTM, point, nil, [][]float64{{8}}, nil, PointM{}},
+		{POINTZ, point, [][]float64{{7}}, [][]float64{{8}}, nil, PointZ{}},
+		{MULTIPOINT, points, nil, nil, nil, MultiPoint{}},
+		{MULTIPOINTM, points, nil, [][]float64{{4, 5, 6}}, nil, MultiPointM{}},
+		{MULTIPOINTZ, points, [][]float64{{1, 2, 3}}, [][]float64{{4, 5, 6}}, nil, MultiPointZ{}},
+		{POLYLINE, lines, nil, nil, nil, PolyLine{}},
+		{POLYLINEM, lines, nil, [][]float64{{10, 11, 12}, {13, 14}}, nil, PolyLineM{}},
+		{POLYLINEZ, lines, [][]float64{{1, 2, 3}, {4, 5}}, [][]float64{{10, 11, 12}, {13, 14}}, nil, PolyLineZ{}},
+		{POLYGON, rings, nil, nil, nil, Polygon{}},
+		{POLYGONM, rings, nil, [][]float64{{1, 2, 3, 4, 1}, {5, 6, 7, 5}}, nil, PolygonM{}},
+		{POLYGONZ, rings, [][]float64{{9, 8, 7, 6, 9}, {5, 4, 3, 5}}, [][]float64{{1, 2, 3, 4, 1}, {5, 6, 7, 5}}, nil, PolygonZ{}},
+		{MULTIPATCH, rings, [][]float64{{9, 8, 7, 6, 9}, {5, 4, 3, 5}}, nil, []int32{OUTERRING, INNERRING}, MultiPatch{}},
+		{MULTIPATCH, lines, [][]float64{{1, 2, 3}, {4, 5}}, [][]float64{{10, 11, 12}, {13, 14}}, []int32{TRIANGLEFAN, TRIANGLESTRIP}, MultiPatch{}},
+	}
+	for _, test := range tests {
+		shape, err := NewShapeData(test.shapeType, test.coordinates, test.z, test.m, test.partTypes)
+		if err != nil {
+			t.Fatalf("type %d: %v", test.shapeType, err)
+		}
+		header, data := readBack(t, test.shapeType, shape, ShapeData{ShapeType: NULLSHAPE, Shape: Null{}})
+		if header.ShapeType != test.shapeType || len(data) != 2 {
+			t.Fatalf("type %d: header type %d, %d records", test.shapeType, header.ShapeType, len(data))
+		}
+		got := data[0]
+		if reflect.TypeOf(got.Shape) != reflect.TypeOf(test.want) || got.ShapeType != test.shapeType {
+			t.Errorf("type %d: shape %T, want %T", test.shapeType, got.Shape, test.want)
+		}
+		if got.RecordNum != 1 || data[1].RecordNum != 2 || data[1].ShapeType != NULLSHAPE {
+			t.Errorf("type %d: records %d and %d of type %d", test.shapeType, got.RecordNum, data[1].RecordNum, data[1].ShapeType)
+		}
+		if !reflect.DeepEqual(got.Coordinates, test.coordinates) {
+			t.Errorf("type %d: coordinates %v, want %v", test.shapeType, got.Coordinates, test.coordinates)
+		}
+		if !reflect.DeepEqual(got.Z, test.z) {
+			t.Errorf("type %d: Z %v, want %v", test.shapeType, got.Z, test.z)
+		}
+		if !reflect.DeepEqual(got.M, test.m) {
+			t.Errorf("type %d: M %v, want %v", test.shapeType, got.M, test.m)
+		}
+		if test.partTypes != nil && !reflect.DeepEqual(got.PartTypes, test.partTypes) {
+			t.Errorf("type %d: part types %v, want %v", test.shapeType, got.PartTypes, test.partTypes)
+		}
+		if len(test.coordinates) > 1 && (got.NumParts != 2 || !reflect.DeepEqual(got.PartCount, []int{len(test.coordinates[0]), len(test.coordinates[0]) + len(test.coordinates[1])})) {
+			t.Errorf("type %d: %d parts ending at %v", test.shapeType, got.NumParts, got.PartCount)
+		}
+	}
+}
+
+func TestReadShapeMeasures(t *testing.T) {
+	lines := [][][2]float64{{{0, 0}, {1, 1}, {2, 0}}, {{5, 5}, {6, 6}}}
+	shape, err := NewShapeData(POLYLINEZ, lines, [][]float64{{1, 2, 3}, {4, 5}}, [][]float64{{10, 11, NoData}, {13, 14}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, data := readBack(t, POLYLINEZ, shape)
+	pl, ok := data[0].Shape.(PolyLineZ)
+	if !ok {
+		t.Fatalf("shape %T", data[0].Shape)
+	}
+	// the record holds the values of all parts in one list, ShapeData splits them per part
+	if !reflect.DeepEqual(pl.Z, []float64{1, 2, 3, 4, 5}) || pl.ZRange != (Range{1, 5}) {
+		t.Errorf("Z %v range %v", pl.Z, pl.ZRange)
+	}
+	if pl.MRange != (Range{10, 14}) || !IsNoData(data[0].M[0][2]) {
+		t.Errorf("M %v range %v, want no data as third value", data[0].M, pl.MRange)
+	}
+	if header.MinZ != 1 || header.MaxZ != 5 || header.MinM != 10 || header.MaxM != 14 {
+		t.Errorf("header Z %g to %g, M %g to %g", header.MinZ, header.MaxZ, header.MinM, header.MaxM)
+	}
+}
+
+func TestReadShapeWithoutM(t *testing.T) {
+	tests := []struct {
+		shapeType   int32
+		coordinates [][][2]float64
+		z           [][]float64
+	}{
+		{POINTZ, [][][2]float64{{{3, 4}}}, [][]float64{{7}}},
+		{MULTIPOINTM, [][][2]float64{{{0, 0}, {1, 2}}}, nil},
+		{MULTIPOINTZ, [][][2]float64{{{0, 0}, {1, 2}}}, [][]float64{{1, 2}}},
+		{POLYLINEM, [][][2]float64{{{0, 0}, {1, 1}}}, nil},
+		{POLYLINEZ, [][][2]float64{{{0, 0}, {1, 1}}}, [][]float64{{1, 2}}},
+		{POLYGONM, [][][2]float64{{{0, 0}, {0, 1}, {1, 0}, {0, 0}}}, nil},
+		{POLYGONZ, [][][2]float64{{{0, 0}, {0, 1}, {1, 0}, {0, 0}}}, [][]float64{{1, 2, 3, 1}}},
+		{MULTIPATCH, [][][2]float64{{{0, 0}, {0, 1}, {1, 0}}}, [][]float64{{1, 2, 3}}},
+	}
+	for _, test := range tests {
+		shape, err := NewShapeData(test.shapeType, test.coordinates, test.z, nil, nil)
+		if err != nil {
+			t.Fatalf("type %d: %v", test.shapeType, err)
+		}
+		// the next record follows directly, its record header must not be read as M values
+		_, data := readBack(t, test.shapeType, shape, shape)
+		for _, got := range data {
+			if got.HasM() {
+				t.Errorf("type %d: M %v, want none", test.shapeType, got.M)
+			}
+			if !reflect.DeepEqual(got.Z, test.z) || !reflect.DeepEqual(got.Coordinates, test.coordinates) {
+				t.Errorf("type %d: coordinates %v Z %v", test.shapeType, got.Coordinates, got.Z)
+			}
+		}
+		if p, ok := data[0].Shape.(PointZ); ok && !IsNoData(p.M) {
+			t.Errorf("PointZ without M has M %g", p.M)
+		}
+	}
+}
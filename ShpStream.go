@@ -0,0 +1,136 @@
+package shpReader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// ShapeReader reads the records of a shapefile one at a time, only the record being decoded is kept in memory.
+// It reads from any io.Reader, e.g. a file, a zip entry or an HTTP response body.
+//
+//	sr, err := NewShapeReader(r)
+//	for {
+//		shape, err := sr.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// With SkipInvalid set, records that can not be decoded are skipped instead of returned as an error,
+// the errors of the skipped records are kept in Skipped. Records with an invalid length can not be skipped.
+type ShapeReader struct {
+	Header      Header
+	SkipInvalid bool
+	Skipped     []error
+	r           io.Reader
+	pos         int64 // bytes read, including the header
+	length      int64 // file length in bytes according to the header
+	records     int32 // records read
+}
+
+// NewShapeReader reads the header from r and returns a reader positioned on the first record
+func NewShapeReader(r io.Reader) (sr *ShapeReader, err error) {
+	sr = &ShapeReader{r: bufio.NewReader(r)}
+	content := make([]byte, 100)
+	if _, err = io.ReadFull(sr.r, content); err != nil {
+		return nil, err
+	}
+	bf := NewFromBytes(content)
+	if sr.Header, err = readHeader(&bf); err != nil {
+		return nil, err
+	}
+	sr.pos = 100
+	sr.length = int64(sr.Header.FileLength) * 2
+	return sr, nil
+}
+
+// Next returns the next record, at the end of the file the error is io.EOF.
+// Problems with a record are returned as a RecordError
+func (sr *ShapeReader) Next() (shapeData ShapeData, err error) {
+	for {
+		if sr.pos >= sr.length {
+			return shapeData, io.EOF
+		}
+		recordHeader := make([]byte, 8)
+		if _, err = io.ReadFull(sr.r, recordHeader); err != nil { // file is shorter than the header states
+			return shapeData, &RecordError{Record: sr.records + 1, Offset: sr.pos, Err: ErrTruncated}
+		}
+		recordNum := int32(binary.BigEndian.Uint32(recordHeader))
+		contentLength := int64(int32(binary.BigEndian.Uint32(recordHeader[4:])))
+		if contentLength < 2 || sr.pos+8+contentLength*2 > sr.length {
+			return shapeData, &RecordError{Record: recordNum, Offset: sr.pos + 4, Err: ErrFileLength}
+		}
+		content, err := readN(sr.r, recordHeader, contentLength*2)
+		if err != nil {
+			return shapeData, &RecordError{Record: recordNum, Offset: sr.pos + 8, Err: err}
+		}
+		offset := sr.pos
+		sr.pos += int64(len(content))
+		sr.records++
+		bf := NewFromBytes(content)
+		shapeData, err = readRecord(&bf, offset, sr.Header.ShapeType)
+		if err != nil && sr.SkipInvalid {
+			sr.Skipped = append(sr.Skipped, err)
+			continue
+		}
+		return shapeData, err
+	}
+}
+
+// readN appends n bytes from r to buf. The buffer grows as the bytes arrive, so a corrupt length in a record header
+// can not allocate more memory than the source holds. A source that ends early returns ErrTruncated
+func readN(r io.Reader, buf []byte, n int64) ([]byte, error) {
+	b := bytes.NewBuffer(buf)
+	if _, err := io.CopyN(b, r, n); err != nil {
+		if err == io.EOF {
+			return nil, ErrTruncated
+		}
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ForEach calls fn with every record until the end of the file, no record is kept after fn returns.
+// It stops at the first error of Next or fn and returns it, at the end of the file it returns nil
+func (sr *ShapeReader) ForEach(fn func(shapeData ShapeData) error) error {
+	for {
+		shapeData, err := sr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(shapeData); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadAll reads the header and all records from r into memory, it is ForEach collecting the records.
+// The records of a large file are better processed one at a time with Next or ForEach
+func ReadAll(r io.Reader) (header Header, data []ShapeData, err error) {
+	sr, err := NewShapeReader(r)
+	if err != nil {
+		return
+	}
+	err = sr.ForEach(func(shapeData ShapeData) error {
+		data = append(data, shapeData)
+		return nil
+	})
+	return sr.Header, data, err
+}
+
+// ReadFile reads the header and all records of a .shp file into memory, see ReadAll
+func ReadFile(filename string) (header Header, data []ShapeData, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	return ReadAll(file)
+}
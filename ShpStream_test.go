@@ -0,0 +1,249 @@
+package shpReader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"testing/iotest"
+)
+
+// shpFile returns the bytes of a .shp file of shapeType with the records, the file length of the header is the
+// length of the records
+func shpFile(shapeType int32, records ...[]byte) []byte {
+	var body []byte
+	for _, record := range records {
+		body = append(body, record...)
+	}
+	buf := new(bytes.Buffer)
+	writeHeader(buf, Header{FileCode: 0x270A, FileLength: int32(100+len(body)) / 2, Version: 1000, ShapeType: shapeType})
+	return append(buf.Bytes(), body...)
+}
+
+// shpRecord returns the record header and the content, the content length is the length of the content
+func shpRecord(recordNum int32, content []byte) []byte {
+	record := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(record, uint32(recordNum))
+	binary.BigEndian.PutUint32(record[4:], uint32(len(content)/2))
+	return append(record, content...)
+}
+
+// setFileLength sets the file length of the header in 16-bit words
+func setFileLength(file []byte, words uint32) []byte {
+	binary.BigEndian.PutUint32(file[24:], words)
+	return file
+}
+
+// line is the content of a PolyLine record of 80 bytes
+var line = encodeShape(PolyLine{Box{0, 0, 1, 1}, 1, 2, []int32{0}, []Point{{0, 0}, {1, 1}}})
+
+func TestReadShapesErrors(t *testing.T) {
+	// the records of line start at 100, 188, 276 ..., their content 8 bytes later
+	valid := shpFile(POLYLINE, shpRecord(1, line), shpRecord(2, line))
+	badCode := append([]byte(nil), valid...)
+	badCode[3] = 0x0B
+	badType := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint32(badType[32:], 2)
+	unknown := encodeShape(Point{1, 2})
+	binary.LittleEndian.PutUint32(unknown, 99)
+	parts := encodeShape(PolyLine{Box{0, 0, 1, 1}, 2, 2, []int32{0, 5}, []Point{{0, 0}, {1, 1}}})
+	points := encodeShape(PolyLine{Box{0, 0, 1, 1}, 1, 10, []int32{0}, []Point{{0, 0}, {1, 1}}})
+	tests := []struct {
+		name   string
+		file   []byte
+		want   error
+		record int32
+		offset int64
+	}{
+		{"file code", badCode, ErrFileCode, 0, 0},
+		{"header shape type", badType, ErrShapeType, 0, 0},
+		{"record shape type", shpFile(POLYLINE, shpRecord(1, line), shpRecord(2, encodeShape(Point{1, 2}))), ErrShapeType, 2, 196},
+		{"unknown shape type", shpFile(POLYLINE, shpRecord(1, unknown)), ErrShapeType, 1, 108},
+		{"part index", shpFile(POLYLINE, shpRecord(1, line), shpRecord(2, parts)), ErrPartIndex, 2, 196 + 84},
+		{"points beyond content", shpFile(POLYLINE, shpRecord(1, points)), ErrTruncated, 1, 108 + 48},
+		{"record beyond file length", setFileLength(append([]byte(nil), valid...), 100), ErrFileLength, 2, 192},
+		{"truncated in record", valid[:len(valid)-10], ErrTruncated, 2, 196},
+		{"truncated record header", valid[:192], ErrTruncated, 2, 188},
+		{"content length 0", shpFile(POLYLINE, shpRecord(1, nil)), ErrFileLength, 1, 104},
+		{"huge content length", huge(), ErrTruncated, 1, 108},
+	}
+	for _, test := range tests {
+		bf := NewFromBytes(test.file)
+		_, _, err := ReadShapes(&bf)
+		checkRecordError(t, "ReadShapes "+test.name, err, test.want, test.record, test.offset)
+		_, _, err = ReadAll(bytes.NewReader(test.file))
+		checkRecordError(t, "ReadAll "+test.name, err, test.want, test.record, test.offset)
+	}
+}
+
+// huge returns a file of 108 bytes whose header and record header state lengths of nearly 4 GiB
+func huge() []byte {
+	file := shpFile(POLYLINE, shpRecord(1, []byte{3, 0, 0, 0}))
+	binary.BigEndian.PutUint32(file[24:], 0x7FFFFFFF)
+	binary.BigEndian.PutUint32(file[104:], 0x7FFFFF00)
+	return file
+}
+
+// checkRecordError checks that err is want, in a RecordError with record and offset unless record is 0
+func checkRecordError(t *testing.T, name string, err, want error, record int32, offset int64) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s: error %v, want %v", name, err, want)
+		return
+	}
+	var recordError *RecordError
+	if record == 0 {
+		if errors.As(err, &recordError) {
+			t.Errorf("%s: error %v in a record, want a header error", name, err)
+		}
+		return
+	}
+	if !errors.As(err, &recordError) || recordError.Record != record || recordError.Offset != offset {
+		t.Errorf("%s: error %v, want record %d at byte %d", name, err, record, offset)
+	}
+}
+
+func TestShapeReaderHugeContentLength(t *testing.T) {
+	sr, err := NewShapeReader(bytes.NewReader(huge()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = sr.Next()
+	runtime.ReadMemStats(&after)
+	checkRecordError(t, "Next", err, ErrTruncated, 1, 108)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Next allocated %d bytes for a record of 4 bytes", allocated)
+	}
+}
+
+func TestShapeReaderSkipInvalid(t *testing.T) {
+	parts := encodeShape(PolyLine{Box{0, 0, 1, 1}, 2, 2, []int32{0, 5}, []Point{{0, 0}, {1, 1}}})
+	file := shpFile(POLYLINE, shpRecord(1, line), shpRecord(2, encodeShape(Point{1, 2})), shpRecord(3, parts),
+		shpRecord(4, encodeShape(Null{})), shpRecord(5, line))
+	sr, err := NewShapeReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr.SkipInvalid = true
+	var records []int32
+	for {
+		shapeData, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, shapeData.RecordNum)
+	}
+	if len(records) != 3 || records[0] != 1 || records[1] != 4 || records[2] != 5 {
+		t.Errorf("records %v, want 1, 4 and 5", records)
+	}
+	if len(sr.Skipped) != 2 {
+		t.Fatalf("skipped %v, want records 2 and 3", sr.Skipped)
+	}
+	checkRecordError(t, "skipped", sr.Skipped[0], ErrShapeType, 2, 196)
+	checkRecordError(t, "skipped", sr.Skipped[1], ErrPartIndex, 3, 224+84)
+
+	// a record with an invalid length ends the file, the records after it can not be found
+	file = setFileLength(shpFile(POLYLINE, shpRecord(1, line), shpRecord(2, line)), 100)
+	sr, err = NewShapeReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr.SkipInvalid = true
+	if _, err = sr.Next(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = sr.Next()
+	checkRecordError(t, "invalid length", err, ErrFileLength, 2, 192)
+}
+
+// chunks returns a reader that is not a file and returns content in pieces of size bytes
+func chunks(content []byte, size int) io.Reader {
+	var readers []io.Reader
+	for start := 0; start < len(content); start += size {
+		end := start + size
+		if end > len(content) {
+			end = len(content)
+		}
+		readers = append(readers, bytes.NewReader(content[start:end]))
+	}
+	return io.MultiReader(readers...)
+}
+
+func TestShapeReaderStream(t *testing.T) {
+	var shapes []ShapeData
+	for i := 0; i < 50; i++ {
+		x := float64(i)
+		rings := [][][2]float64{{{x, 0}, {x, 3}, {x + 3, 3}, {x + 3, 0}, {x, 0}}}
+		if i%4 == 0 {
+			rings = append(rings, [][2]float64{{x + 1, 1}, {x + 2, 1}, {x + 2, 2}, {x + 1, 1}})
+		}
+		shape, err := NewShapeData(POLYGON, rings, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%10 == 5 {
+			shape = ShapeData{ShapeType: NULLSHAPE, Shape: Null{}}
+		}
+		shapes = append(shapes, shape)
+	}
+	filename := filepath.Join(t.TempDir(), "stream.shp")
+	if err := WriteShapefile(filename, POLYGON, shapes, nil); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bf := NewFromBytes(content)
+	wantHeader, want, err := ReadShapes(&bf)
+	if err != nil || len(want) != len(shapes) {
+		t.Fatalf("ReadShapes: %d records, %v", len(want), err)
+	}
+
+	sr, err := NewShapeReader(chunks(content, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []ShapeData
+	if err = sr.ForEach(func(shapeData ShapeData) error {
+		got = append(got, shapeData)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if sr.Header != wantHeader || !reflect.DeepEqual(got, want) {
+		t.Errorf("ForEach on chunks of 7 bytes differs from ReadShapes")
+	}
+	header, got, err := ReadAll(iotest.OneByteReader(bytes.NewReader(content)))
+	if err != nil || header != wantHeader || !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAll one byte at a time differs from ReadShapes: %v", err)
+	}
+
+	// ForEach stops at the first error of fn
+	stop := errors.New("stop")
+	sr, err = NewShapeReader(chunks(content, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	err = sr.ForEach(func(ShapeData) error {
+		calls++
+		if calls == 3 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || calls != 3 {
+		t.Errorf("ForEach returned %v after %d calls, want stop after 3", err, calls)
+	}
+}
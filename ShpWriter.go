@@ -0,0 +1,495 @@
+package shpReader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Writer writes shapes to a .shp file with the .shx index and the .dbf attribute table next to it.
+// The headers are written by Close, when the bounding box and the file lengths are known.
+type Writer struct {
+	Header  Header
+	Fields  []Field
+	files   [3]*os.File // .shp, .shx, .dbf
+	writers [3]*bufio.Writer
+	pos     int64 // length of the .shp file in bytes
+	records int32
+	hasBox  bool
+	fieldID bool // no fields given, the record number is written as ID
+}
+
+// Create creates filename (.shp) and the .shx and .dbf files next to it for shapes of shapeType.
+// fields describe the attributes that are written to the .dbf file from ShapeData.Attributes,
+// without fields a numeric ID field with the record number is written. Field names are at most 10 bytes and
+// unique regardless of case, lengths are 1 to 255 bytes and the types are C, N, F, D or L
+func Create(filename string, shapeType int32, fields []Field) (w *Writer, err error) {
+	if !validShapeType(shapeType) {
+		return nil, fmt.Errorf("%w %d", ErrShapeType, shapeType)
+	}
+	if err = checkFields(fields); err != nil {
+		return nil, err
+	}
+	w = &Writer{Fields: fields}
+	w.Header = Header{FileCode: 0x270A, Version: 1000, ShapeType: shapeType}
+	if len(w.Fields) == 0 {
+		w.Fields = []Field{{Name: "ID", Type: 'N', Length: 10}}
+		w.fieldID = true
+	}
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for i, name := range []string{base + ".shp", base + ".shx", base + ".dbf"} {
+		if w.files[i], err = os.Create(name); err != nil {
+			w.closeFiles()
+			return nil, err
+		}
+		w.writers[i] = bufio.NewWriter(w.files[i])
+	}
+	// room for the headers, written on Close
+	w.writers[0].Write(make([]byte, 100))
+	w.writers[1].Write(make([]byte, 100))
+	w.writers[2].Write(make([]byte, 32+32*len(w.Fields)+1))
+	w.pos = 100
+	return w, nil
+}
+
+// Write adds a record, shapeData must be of the shape type of the file or a Null shape.
+// The record is built from the generic fields (ShapeType, Coordinates, Z, M, PartTypes),
+// Shape and RecordNum are not used so changed coordinates are written as they are.
+func (w *Writer) Write(shapeData ShapeData) error {
+	if shapeData.ShapeType != NULLSHAPE && shapeData.ShapeType != w.Header.ShapeType {
+		return fmt.Errorf("%w %d in a file of shape type %d", ErrShapeType, shapeData.ShapeType, w.Header.ShapeType)
+	}
+	shape, err := buildShape(shapeData)
+	if err != nil {
+		return err
+	}
+	content := encodeShape(shape)
+	w.records++
+	recordHeader := make([]byte, 8)
+	binary.BigEndian.PutUint32(recordHeader, uint32(w.records))
+	binary.BigEndian.PutUint32(recordHeader[4:], uint32(len(content)/2))
+	index := make([]byte, 8)
+	binary.BigEndian.PutUint32(index, uint32(w.pos/2))
+	binary.BigEndian.PutUint32(index[4:], uint32(len(content)/2))
+	w.writers[0].Write(recordHeader)
+	w.writers[0].Write(content)
+	w.writers[1].Write(index)
+	w.pos += int64(len(recordHeader) + len(content))
+	w.extendHeader(newShapeData(w.records, int32(len(content)/2), shape))
+
+	attributes := shapeData.Attributes
+	if w.fieldID {
+		attributes = Attributes{"ID": int64(w.records)}
+	}
+	record := []byte{' '}
+	for _, field := range w.Fields {
+		record = append(record, formatValue(field, attributes[field.Name])...)
+	}
+	_, err = w.writers[2].Write(record)
+	return err
+}
+
+// extendHeader extends the bounding box and the Z and M ranges of the header with the shape
+func (w *Writer) extendHeader(s ShapeData) {
+	if s.ShapeType == NULLSHAPE {
+		return
+	}
+	h := &w.Header
+	if !w.hasBox {
+		h.MinX, h.MinY, h.MaxX, h.MaxY = s.Box0, s.Box1, s.Box2, s.Box3
+		h.MinZ, h.MaxZ, h.MinM, h.MaxM = math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+		w.hasBox = true
+	}
+	h.MinX, h.MinY = math.Min(h.MinX, s.Box0), math.Min(h.MinY, s.Box1)
+	h.MaxX, h.MaxY = math.Max(h.MaxX, s.Box2), math.Max(h.MaxY, s.Box3)
+	for _, part := range s.Z {
+		for _, z := range part {
+			h.MinZ, h.MaxZ = math.Min(h.MinZ, z), math.Max(h.MaxZ, z)
+		}
+	}
+	for _, part := range s.M {
+		for _, m := range part {
+			if !IsNoData(m) {
+				h.MinM, h.MaxM = math.Min(h.MinM, m), math.Max(h.MaxM, m)
+			}
+		}
+	}
+}
+
+// Close writes the headers and closes the files
+func (w *Writer) Close() (err error) {
+	defer func() {
+		if e := w.closeFiles(); err == nil {
+			err = e
+		}
+	}()
+	if _, err = w.writers[2].Write([]byte{0x1A}); err != nil { // end of file marker
+		return
+	}
+	for _, writer := range w.writers {
+		if err = writer.Flush(); err != nil {
+			return
+		}
+	}
+	header := w.Header
+	if math.IsInf(header.MinZ, 0) { // no Z values
+		header.MinZ, header.MaxZ = 0, 0
+	}
+	if math.IsInf(header.MinM, 0) {
+		header.MinM, header.MaxM = 0, 0
+	}
+	buf := new(bytes.Buffer)
+	header.FileLength = int32(w.pos / 2)
+	writeHeader(buf, header)
+	header.FileLength = (100 + 8*w.records) / 2
+	writeHeader(buf, header)
+	now := time.Now()
+	dbfHeader := DbfHeader{
+		Version:      3,
+		Year:         byte(now.Year() - 1900),
+		Month:        byte(now.Month()),
+		Day:          byte(now.Day()),
+		NumRecords:   uint32(w.records),
+		HeaderLength: uint16(32 + 32*len(w.Fields) + 1),
+		RecordLength: 1,
+	}
+	for _, field := range w.Fields {
+		dbfHeader.RecordLength += uint16(field.Length)
+	}
+	binary.Write(buf, binary.LittleEndian, dbfHeader)
+	for _, field := range w.Fields {
+		desc := make([]byte, 32)
+		copy(desc[:10], field.Name) // 11th byte is the terminating 0
+		desc[11] = field.Type
+		desc[16] = byte(field.Length)
+		desc[17] = byte(field.Decimals)
+		buf.Write(desc)
+	}
+	buf.WriteByte(0x0D)
+	headers := buf.Bytes()
+	if _, err = w.files[0].WriteAt(headers[:100], 0); err != nil {
+		return
+	}
+	if _, err = w.files[1].WriteAt(headers[100:200], 0); err != nil {
+		return
+	}
+	_, err = w.files[2].WriteAt(headers[200:], 0)
+	return
+}
+
+// checkFields reports the first field descriptor that can not be stored in a .dbf header
+func checkFields(fields []Field) error {
+	names := make(map[string]bool, len(fields))
+	recordLength := 1
+	for _, field := range fields {
+		switch {
+		case field.Name == "" || len(field.Name) > 10:
+			return fmt.Errorf("field name %q is not 1 to 10 bytes long", field.Name)
+		case names[strings.ToUpper(field.Name)]:
+			return fmt.Errorf("field name %s is used twice", field.Name)
+		case !strings.ContainsRune("CNFDL", rune(field.Type)):
+			return fmt.Errorf("field %s: unknown field type %q", field.Name, field.Type)
+		case field.Length < 1 || field.Length > 255:
+			return fmt.Errorf("field %s: length %d is not 1 to 255", field.Name, field.Length)
+		case field.Decimals < 0 || field.Decimals > field.Length:
+			return fmt.Errorf("field %s: %d decimals in length %d", field.Name, field.Decimals, field.Length)
+		}
+		names[strings.ToUpper(field.Name)] = true
+		recordLength += field.Length
+	}
+	if recordLength > math.MaxUint16 {
+		return fmt.Errorf("record length %d is larger than %d", recordLength, math.MaxUint16)
+	}
+	return nil
+}
+
+func (w *Writer) closeFiles() (err error) {
+	for _, file := range w.files {
+		if file != nil {
+			if e := file.Close(); err == nil {
+				err = e
+			}
+		}
+	}
+	return
+}
+
+// WriteShapefile writes all shapes to filename (.shp) with the .shx and .dbf files, see Create
+func WriteShapefile(filename string, shapeType int32, shapes []ShapeData, fields []Field) error {
+	w, err := Create(filename, shapeType, fields)
+	if err != nil {
+		return err
+	}
+	for _, shapeData := range shapes {
+		if err = w.Write(shapeData); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// formatValue formats a value in exactly the field length in bytes, numbers that do not fit are written as ***.
+// Text that does not fit is cut at the last UTF-8 character that fits
+func formatValue(field Field, value interface{}) []byte {
+	text := ""
+	switch field.Type {
+	case 'N', 'F':
+		switch v := value.(type) {
+		case float64:
+			text = strconv.FormatFloat(v, 'f', field.Decimals, 64)
+		case int64:
+			text = strconv.FormatFloat(float64(v), 'f', field.Decimals, 64)
+		case int:
+			text = strconv.FormatFloat(float64(v), 'f', field.Decimals, 64)
+		}
+		if len(text) > field.Length {
+			text = strings.Repeat("*", field.Length)
+		}
+		return []byte(strings.Repeat(" ", field.Length-len(text)) + text)
+	case 'D':
+		if v, ok := value.(time.Time); ok {
+			text = v.Format("20060102")
+		}
+	case 'L':
+		text = "?"
+		if v, ok := value.(bool); ok {
+			text = map[bool]string{true: "T", false: "F"}[v]
+		}
+	default:
+		text = Attributes{field.Name: value}.String(field.Name)
+	}
+	if len(text) > field.Length {
+		n := field.Length
+		for n > 0 && !utf8.RuneStart(text[n]) {
+			n--
+		}
+		text = text[:n]
+	}
+	return []byte(text + strings.Repeat(" ", field.Length-len(text)))
+}
+
+// NewShapeData creates a record of shapeType from coordinates in the layout of ShapeData,
+// z, m and partTypes may be nil. The bounding box, counts and Shape are calculated
+func NewShapeData(shapeType int32, coordinates [][][2]float64, z, m [][]float64, partTypes []int32) (ShapeData, error) {
+	shape, err := buildShape(ShapeData{ShapeType: shapeType, Coordinates: coordinates, Z: z, M: m, PartTypes: partTypes})
+	if err != nil {
+		return ShapeData{}, err
+	}
+	return newShapeData(0, int32(len(encodeShape(shape))/2), shape), nil
+}
+
+// NewTriangleMultiPatch creates a MultiPatch record with a triangle strip part for every triangle,
+// every 3 vertices (X, Y, Z) form a triangle
+func NewTriangleMultiPatch(vertices [][3]float64) (ShapeData, error) {
+	var coordinates [][][2]float64
+	var z [][]float64
+	var partTypes []int32
+	for i := 0; i+2 < len(vertices); i += 3 {
+		a, b, c := vertices[i], vertices[i+1], vertices[i+2]
+		coordinates = append(coordinates, [][2]float64{{a[0], a[1]}, {b[0], b[1]}, {c[0], c[1]}})
+		z = append(z, []float64{a[2], b[2], c[2]})
+		partTypes = append(partTypes, TRIANGLESTRIP)
+	}
+	return NewShapeData(MULTIPATCH, coordinates, z, nil, partTypes)
+}
+
+// NewTrianglePolygonZ creates a PolygonZ record with a clockwise ring for every triangle,
+// every 3 vertices (X, Y, Z) form a triangle
+func NewTrianglePolygonZ(vertices [][3]float64) (ShapeData, error) {
+	var coordinates [][][2]float64
+	var z [][]float64
+	for i := 0; i+2 < len(vertices); i += 3 {
+		a, b, c := vertices[i], vertices[i+1], vertices[i+2]
+		if (b[0]-a[0])*(c[1]-a[1])-(b[1]-a[1])*(c[0]-a[0]) > 0 { // counterclockwise
+			b, c = c, b
+		}
+		coordinates = append(coordinates, [][2]float64{{a[0], a[1]}, {b[0], b[1]}, {c[0], c[1]}, {a[0], a[1]}})
+		z = append(z, []float64{a[2], b[2], c[2], a[2]})
+	}
+	return NewShapeData(POLYGONZ, coordinates, z, nil, nil)
+}
+
+// buildShape creates the Shape of the record from the generic fields of ShapeData
+func buildShape(s ShapeData) (shape Shape, err error) {
+	var points []Point
+	var parts []int32
+	for _, part := range s.Coordinates {
+		parts = append(parts, int32(len(points)))
+		for _, c := range part {
+			points = append(points, Point{c[0], c[1]})
+		}
+	}
+	z, err := flatten(s.Z, s.Coordinates, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Z: %v", err)
+	}
+	m, err := flatten(s.M, s.Coordinates, NoData)
+	if err != nil {
+		return nil, fmt.Errorf("M: %v", err)
+	}
+	box := boxOf(points)
+	n := int32(len(points))
+	zRange := rangeOf(z)
+	var mRange Range
+	var mValues []float64 // M values are optional, only written when given
+	if s.M != nil {
+		mRange, mValues = rangeOf(m), m
+	}
+	switch s.ShapeType {
+	case NULLSHAPE:
+		return Null{}, nil
+	case POINT, POINTM, POINTZ:
+		if len(points) != 1 {
+			return nil, fmt.Errorf("%d points for shape type %d", len(points), s.ShapeType)
+		}
+		switch s.ShapeType {
+		case POINTM:
+			return PointM{points[0].X, points[0].Y, m[0]}, nil
+		case POINTZ:
+			return PointZ{points[0].X, points[0].Y, z[0], m[0]}, nil
+		}
+		return points[0], nil
+	case MULTIPOINT:
+		return MultiPoint{box, n, points}, nil
+	case MULTIPOINTM:
+		return MultiPointM{MultiPoint{box, n, points}, mRange, mValues}, nil
+	case MULTIPOINTZ:
+		return MultiPointZ{MultiPoint{box, n, points}, zRange, z, mRange, mValues}, nil
+	}
+	pl := PolyLine{box, int32(len(parts)), n, parts, points}
+	switch s.ShapeType {
+	case POLYLINE:
+		return pl, nil
+	case POLYLINEM:
+		return PolyLineM{pl, mRange, mValues}, nil
+	case POLYLINEZ:
+		return PolyLineZ{pl, zRange, z, mRange, mValues}, nil
+	case POLYGON:
+		return Polygon(pl), nil
+	case POLYGONM:
+		return PolygonM{Polygon(pl), mRange, mValues}, nil
+	case POLYGONZ:
+		return PolygonZ{Polygon(pl), zRange, z, mRange, mValues}, nil
+	case MULTIPATCH:
+		partTypes := s.PartTypes
+		if partTypes == nil {
+			partTypes = make([]int32, len(parts))
+			for i := range partTypes {
+				partTypes[i] = RING
+			}
+		}
+		if len(partTypes) != len(parts) {
+			return nil, fmt.Errorf("%d part types for %d parts", len(partTypes), len(parts))
+		}
+		return MultiPatch{box, pl.NumParts, n, parts, partTypes, points, zRange, z, mRange, mValues}, nil
+	}
+	return nil, fmt.Errorf("%w %d", ErrShapeType, s.ShapeType)
+}
+
+// flatten returns the values of all parts in one list, missing values are set to empty
+func flatten(values [][]float64, coordinates [][][2]float64, empty float64) (flat []float64, err error) {
+	if values != nil && len(values) != len(coordinates) {
+		return nil, fmt.Errorf("%d parts for %d parts of coordinates", len(values), len(coordinates))
+	}
+	for i, part := range coordinates {
+		if values == nil {
+			for range part {
+				flat = append(flat, empty)
+			}
+			continue
+		}
+		if len(values[i]) != len(part) {
+			return nil, fmt.Errorf("part %d: %d values for %d points", i, len(values[i]), len(part))
+		}
+		flat = append(flat, values[i]...)
+	}
+	return
+}
+
+func boxOf(points []Point) (box Box) {
+	for i, p := range points {
+		if i == 0 {
+			box = Box{p.X, p.Y, p.X, p.Y}
+		}
+		box.MinX, box.MinY = math.Min(box.MinX, p.X), math.Min(box.MinY, p.Y)
+		box.MaxX, box.MaxY = math.Max(box.MaxX, p.X), math.Max(box.MaxY, p.Y)
+	}
+	return
+}
+
+// rangeOf returns the range of the values ignoring "no data" values
+func rangeOf(flat []float64) (r Range) {
+	first := true
+	for _, value := range flat {
+		if IsNoData(value) {
+			continue
+		}
+		if first {
+			r = Range{value, value}
+			first = false
+		}
+		r.Min, r.Max = math.Min(r.Min, value), math.Max(r.Max, value)
+	}
+	return
+}
+
+// encodeShape returns the content of a record in the layout of the file, including the shape type
+func encodeShape(shape Shape) []byte {
+	buf := new(bytes.Buffer)
+	write := func(values ...interface{}) {
+		for _, value := range values {
+			binary.Write(buf, binary.LittleEndian, value)
+		}
+	}
+	measures := func(r Range, values []float64) {
+		if values != nil {
+			write(r, values)
+		}
+	}
+	write(shape.Type())
+	switch s := shape.(type) {
+	case Point:
+		write(s)
+	case PointM:
+		write(s)
+	case PointZ:
+		write(s)
+	case MultiPoint:
+		write(s.Box, s.NumPoints, s.Points)
+	case MultiPointM:
+		write(s.Box, s.NumPoints, s.Points)
+		measures(s.MRange, s.M)
+	case MultiPointZ:
+		write(s.Box, s.NumPoints, s.Points, s.ZRange, s.Z)
+		measures(s.MRange, s.M)
+	case PolyLine:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.Points)
+	case Polygon:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.Points)
+	case PolyLineM:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.Points)
+		measures(s.MRange, s.M)
+	case PolygonM:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.Points)
+		measures(s.MRange, s.M)
+	case PolyLineZ:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.Points, s.ZRange, s.Z)
+		measures(s.MRange, s.M)
+	case PolygonZ:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.Points, s.ZRange, s.Z)
+		measures(s.MRange, s.M)
+	case MultiPatch:
+		write(s.Box, s.NumParts, s.NumPoints, s.Parts, s.PartTypes, s.Points, s.ZRange, s.Z)
+		measures(s.MRange, s.M)
+	}
+	return buf.Bytes()
+}
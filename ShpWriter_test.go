@@ -0,0 +1,100 @@
+package shpReader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteShapefileRoundTrip(t *testing.T) {
+	fields := []Field{
+		{Name: "NAME", Type: 'C', Length: 10},
+		{Name: "POP", Type: 'N', Length: 8},
+		{Name: "AREA", Type: 'N', Length: 8, Decimals: 2},
+		{Name: "FOUNDED", Type: 'D', Length: 8},
+		{Name: "CAPITAL", Type: 'L', Length: 1},
+	}
+	names := []string{"Zürich", "Zürich", "Zürich", "Genève", "Neuchâtel-Sud", "Basel"}
+	var shapes []ShapeData
+	for i, name := range names {
+		x := float64(i)
+		shape, err := NewShapeData(POLYGON, [][][2]float64{{{x, 0}, {x, 1}, {x + 1, 1}, {x + 1, 0}, {x, 0}}}, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shape.Attributes = Attributes{
+			"NAME":    name,
+			"POP":     int64(1000 * (i + 1)),
+			"AREA":    87.88 + x,
+			"FOUNDED": time.Date(1200+i, 1, 2, 0, 0, 0, 0, time.UTC),
+			"CAPITAL": i == 3,
+		}
+		shapes = append(shapes, shape)
+	}
+	filename := filepath.Join(t.TempDir(), "cities.shp")
+	if err := WriteShapefile(filename, POLYGON, shapes, fields); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(strings.TrimSuffix(filename, ".shp") + ".dbf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(32 + 32*len(fields) + 1 + len(names)*(1+10+8+8+8+1) + 1); info.Size() != want {
+		t.Errorf(".dbf has %d bytes, want %d", info.Size(), want)
+	}
+	dataset, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dataset.Shapes) != len(names) {
+		t.Fatalf("%d shapes, want %d", len(dataset.Shapes), len(names))
+	}
+	for i, shape := range dataset.Shapes {
+		a := shape.Attributes
+		name := names[i]
+		if name == "Neuchâtel-Sud" {
+			name = "Neuchâtel" // cut to 10 bytes at a character boundary
+		}
+		if a.String("NAME") != name {
+			t.Errorf("record %d: NAME %q, want %q", i+1, a.String("NAME"), name)
+		}
+		if v, ok := a.Int("POP"); !ok || v != int64(1000*(i+1)) {
+			t.Errorf("record %d: POP %v", i+1, a["POP"])
+		}
+		if v, ok := a.Float("AREA"); !ok || v != 87.88+float64(i) {
+			t.Errorf("record %d: AREA %v", i+1, a["AREA"])
+		}
+		if v, ok := a.Date("FOUNDED"); !ok || v.Year() != 1200+i {
+			t.Errorf("record %d: FOUNDED %v", i+1, a["FOUNDED"])
+		}
+		if v, ok := a.Bool("CAPITAL"); !ok || v != (i == 3) {
+			t.Errorf("record %d: CAPITAL %v", i+1, a["CAPITAL"])
+		}
+		if shape.Coordinates[0][2] != [2]float64{float64(i) + 1, 1} {
+			t.Errorf("record %d: coordinates %v", i+1, shape.Coordinates)
+		}
+	}
+}
+
+func TestCreateChecksFields(t *testing.T) {
+	for _, fields := range [][]Field{
+		{{Name: "NAME", Type: 'C', Length: 300}},
+		{{Name: "NAME", Type: 'C', Length: 0}},
+		{{Name: "POPULATION1", Type: 'N', Length: 10}},
+		{{Name: "", Type: 'N', Length: 10}},
+		{{Name: "NAME", Type: 'C', Length: 10}, {Name: "name", Type: 'C', Length: 10}},
+		{{Name: "MEMO", Type: 'M', Length: 10}},
+		{{Name: "AREA", Type: 'N', Length: 4, Decimals: 6}},
+	} {
+		filename := filepath.Join(t.TempDir(), "fields.shp")
+		if w, err := Create(filename, POINT, fields); err == nil {
+			w.Close()
+			t.Errorf("fields %v accepted", fields)
+		}
+		if _, err := os.Stat(filename); err == nil {
+			t.Errorf("fields %v: file created", fields)
+		}
+	}
+}
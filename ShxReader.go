@@ -0,0 +1,147 @@
+package shpReader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+/*
+The index file (.shx) has the same 100 byte header as the .shp file, followed by a fixed length record
+for every record in the .shp file:
+   Bytes Type  Endianness Usage
+   0–3   int32 big        Offset of the record in the .shp file (in 16-bit words)
+   4–7   int32 big        Content length of the record (in 16-bit words)
+*/
+
+// IndexRecord is an entry of the .shx index, Offset and ContentLength are in 16-bit words like in the file
+type IndexRecord struct {
+	Offset        int32
+	ContentLength int32
+}
+
+// ReadIndex reads the header and all entries of a .shx file
+func ReadIndex(bf *BinFileReader) (header Header, index []IndexRecord, err error) {
+	header, err = readHeader(bf)
+	if err != nil {
+		return
+	}
+	for bf.pos+8 <= bf.length {
+		index = append(index, IndexRecord{Offset: bf.ReadIntLittle(), ContentLength: bf.ReadIntLittle()})
+	}
+	return
+}
+
+// IndexedReader reads individual records of a .shp file, using the .shx index to find them
+// without reading the records in front of them
+type IndexedReader struct {
+	Header Header
+	Index  []IndexRecord
+	shp    io.ReaderAt
+	size   int64 // bytes in shp, the file length of the header when shp does not know its size
+	closer io.Closer
+}
+
+// NewIndexedReader creates a reader on the .shp content in shp with the index read from shx
+func NewIndexedReader(shp io.ReaderAt, shx *BinFileReader) (r *IndexedReader, err error) {
+	r = &IndexedReader{shp: shp}
+	if _, r.Index, err = ReadIndex(shx); err != nil {
+		return nil, err
+	}
+	content := make([]byte, 100)
+	if _, err = shp.ReadAt(content, 0); err != nil {
+		return nil, err
+	}
+	bf := NewFromBytes(content)
+	if r.Header, err = readHeader(&bf); err != nil {
+		return nil, err
+	}
+	r.size = int64(r.Header.FileLength) * 2
+	if size, ok := readerSize(shp); ok {
+		r.size = size
+	}
+	return r, nil
+}
+
+// readerSize returns the size of readers that know it, like os.File, bytes.Reader and io.SectionReader
+func readerSize(r interface{}) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Size() int64 }:
+		return r.Size(), true
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := r.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size(), true
+		}
+	}
+	return 0, false
+}
+
+// OpenIndexed opens a .shp file and the .shx file next to it for random access, Close closes the .shp file
+func OpenIndexed(filename string) (r *IndexedReader, err error) {
+	shxName := SidecarName(filename, ".shx")
+	if shxName == "" {
+		return nil, errors.New("No index (.shx) for " + filename)
+	}
+	shx, err := New(shxName)
+	if err != nil {
+		return
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	if r, err = NewIndexedReader(file, &shx); err != nil {
+		file.Close()
+		return nil, err
+	}
+	r.closer = file
+	return r, nil
+}
+
+// NumRecords returns the number of records in the index
+func (r *IndexedReader) NumRecords() int {
+	return len(r.Index)
+}
+
+// ReadRecord reads record n, record numbers start at 1 like RecordNum. An index entry beyond the file length of the
+// header returns ErrFileLength, an entry beyond the end of the .shp file ErrTruncated
+func (r *IndexedReader) ReadRecord(n int) (shapeData ShapeData, err error) {
+	if n < 1 || n > len(r.Index) {
+		return shapeData, fmt.Errorf("record %d not in index of %d records", n, len(r.Index))
+	}
+	entry := r.Index[n-1]
+	offset, length := int64(entry.Offset)*2, 8+int64(entry.ContentLength)*2 // record header + content
+	if entry.Offset < 50 || entry.ContentLength < 2 || offset+length > int64(r.Header.FileLength)*2 {
+		return shapeData, &RecordError{Record: int32(n), Offset: offset, Err: ErrFileLength}
+	}
+	if offset+length > r.size {
+		return shapeData, &RecordError{Record: int32(n), Offset: offset, Err: ErrTruncated}
+	}
+	content, err := readN(io.NewSectionReader(r.shp, offset, length), nil, length)
+	if err != nil {
+		return shapeData, &RecordError{Record: int32(n), Offset: offset, Err: err}
+	}
+	bf := NewFromBytes(content)
+	return readRecord(&bf, offset, r.Header.ShapeType)
+}
+
+// ReadRecords reads the records from up to and including to
+func (r *IndexedReader) ReadRecords(from, to int) (data []ShapeData, err error) {
+	for n := from; n <= to; n++ {
+		shapeData, err := r.ReadRecord(n)
+		if err != nil {
+			return data, err
+		}
+		data = append(data, shapeData)
+	}
+	return
+}
+
+// Close closes the .shp file if it was opened by OpenIndexed
+func (r *IndexedReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
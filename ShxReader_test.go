@@ -0,0 +1,157 @@
+package shpReader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeLines writes n PolyLine shapes of 2 to n+1 points and returns the name of the .shp file
+func writeLines(t *testing.T, n int) string {
+	var shapes []ShapeData
+	for i := 0; i < n; i++ {
+		var line [][2]float64
+		for j := 0; j < i+2; j++ {
+			line = append(line, [2]float64{float64(i), float64(j)})
+		}
+		shape, err := NewShapeData(POLYLINE, [][][2]float64{line}, nil, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shapes = append(shapes, shape)
+	}
+	filename := filepath.Join(t.TempDir(), "lines.shp")
+	if err := WriteShapefile(filename, POLYLINE, shapes, nil); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestReadIndex(t *testing.T) {
+	filename := writeLines(t, 5)
+	shx, err := New(strings.TrimSuffix(filename, ".shp") + ".shx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, index, err := ReadIndex(&shx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.ShapeType != POLYLINE || header.FileLength != 50+4*5 || len(index) != 5 {
+		t.Fatalf("header %v with %d entries", header, len(index))
+	}
+	offset := int32(50)
+	for i, entry := range index {
+		// shape type, box, number of parts and points, 1 part index and the points
+		if want := int32(4+32+4+4+4+16*(i+2)) / 2; entry.Offset != offset || entry.ContentLength != want {
+			t.Errorf("entry %d: %v, want offset %d and length %d", i, entry, offset, want)
+		}
+		offset += 4 + entry.ContentLength
+	}
+	bad := NewFromBytes([]byte("not a shapefile"))
+	if _, _, err = ReadIndex(&bad); err == nil {
+		t.Error("no error for a file without header")
+	}
+}
+
+func TestOpenIndexed(t *testing.T) {
+	filename := writeLines(t, 5)
+	r, err := OpenIndexed(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	bf, err := New(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, want, err := ReadShapes(&bf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.NumRecords() != 5 {
+		t.Fatalf("%d records, want 5", r.NumRecords())
+	}
+	for n := 5; n >= 1; n-- {
+		shapeData, err := r.ReadRecord(n)
+		if err != nil || !reflect.DeepEqual(shapeData, want[n-1]) {
+			t.Errorf("record %d: %v %v", n, shapeData, err)
+		}
+	}
+	data, err := r.ReadRecords(2, 4)
+	if err != nil || !reflect.DeepEqual(data, want[1:4]) {
+		t.Errorf("records 2 to 4: %d records, %v", len(data), err)
+	}
+	for _, n := range []int{0, -1, 6} {
+		if _, err = r.ReadRecord(n); err == nil {
+			t.Errorf("record %d: no error", n)
+		}
+	}
+	if data, err = r.ReadRecords(4, 6); err == nil || len(data) != 2 {
+		t.Errorf("records 4 to 6: %d records, %v", len(data), err)
+	}
+	if _, err = OpenIndexed(filepath.Join(t.TempDir(), "none.shp")); err == nil {
+		t.Error("no error without .shx file")
+	}
+}
+
+func TestReadRecordBadIndex(t *testing.T) {
+	filename := writeLines(t, 3)
+	shp, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shx, err := os.ReadFile(strings.TrimSuffix(filename, ".shp") + ".shx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// entry sets index entry n to offset and content length in 16-bit words, of a copy of the .shx file
+	entry := func(n int, offset, length uint32) *BinFileReader {
+		index := append([]byte(nil), shx...)
+		binary.BigEndian.PutUint32(index[100+8*(n-1):], offset)
+		binary.BigEndian.PutUint32(index[104+8*(n-1):], length)
+		bf := NewFromBytes(index)
+		return &bf
+	}
+	// the file length of the header is far beyond the end of the file
+	longHeader := append([]byte(nil), shp...)
+	binary.BigEndian.PutUint32(longHeader[24:], 0x7FFFFFFF)
+	tests := []struct {
+		name  string
+		shp   []byte
+		n     int
+		index *BinFileReader
+		want  error
+	}{
+		{"offset in header", shp, 2, entry(2, 10, 20), ErrFileLength},
+		{"content length 0", shp, 2, entry(2, 50, 0), ErrFileLength},
+		{"beyond file length", shp, 3, entry(3, 50, uint32(len(shp))), ErrFileLength},
+		{"beyond end of file", longHeader, 3, entry(3, 50, 0x7FFFFF00), ErrTruncated},
+	}
+	for _, test := range tests {
+		r, err := NewIndexedReader(bytes.NewReader(test.shp), test.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var recordError *RecordError
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err = r.ReadRecord(test.n)
+		runtime.ReadMemStats(&after)
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+			t.Errorf("%s: %d bytes allocated", test.name, allocated)
+		}
+		if !errors.Is(err, test.want) || !errors.As(err, &recordError) || recordError.Record != int32(test.n) {
+			t.Errorf("%s: %v, want %v in record %d", test.name, err, test.want, test.n)
+		}
+		if _, err = r.ReadRecord(1); err != nil {
+			t.Errorf("%s: record 1: %v", test.name, err)
+		}
+	}
+}
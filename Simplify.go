@@ -0,0 +1,493 @@
+package Triangulate
+
+import (
+	"container/heap"
+	"math"
+)
+
+// SimplifyMethod selects the line simplification algorithm of SimplifyLine and SimplifyRings
+type SimplifyMethod int
+
+const (
+	DouglasPeucker     SimplifyMethod = iota // keeps the points further than the tolerance from the simplified line
+	Visvalingam                              // removes the points with the smallest triangle with their neighbours, smoother at small scale
+	TopologyPreserving                       // Douglas-Peucker without new crossings between lines or rings and without moving points of other rings to the other side
+)
+
+// SimplifyLine removes points of an open line, the first and the last point are kept. The tolerance is in map units:
+// Douglas-Peucker keeps the points further than tolerance from the simplified line, Visvalingam removes points while
+// the triangle of a point with its neighbours is smaller than tolerance². A tolerance of 0 keeps all points
+func SimplifyLine(points []Point, method SimplifyMethod, tolerance float64) []Point {
+	if tolerance <= 0 || len(points) < 3 {
+		return append([]Point(nil), points...)
+	}
+	var keep []bool
+	switch method {
+	case Visvalingam:
+		keep = visvalingam(points, false, tolerance)
+	case TopologyPreserving:
+		keep = simplifyTopology([]*chain{newChain(points, false)}, tolerance)[0]
+	default:
+		keep = make([]bool, len(points))
+		keep[0], keep[len(points)-1] = true, true
+		douglasPeucker(points, 0, len(points)-1, tolerance, keep)
+	}
+	return keptPoints(points, keep)
+}
+
+// SimplifyRings simplifies the rings of a shape, the result has a closed ring for every ring.
+// Rings that become smaller than the tolerance collapse to an empty ring, with TopologyPreserving every ring keeps
+// at least 3 points and the rings keep their relations: no ring crosses itself or another ring and no point of a ring
+// moves to the other side of another ring. The tolerance is in map units like the tolerance of SimplifyLine
+func SimplifyRings(rings []*Poly, method SimplifyMethod, tolerance float64) []*Poly {
+	simplified := make([]*Poly, len(rings))
+	open := make([][]Point, len(rings))
+	for i, ring := range rings {
+		open[i] = openRing(ring.P)
+	}
+	if tolerance <= 0 {
+		for i := range rings {
+			simplified[i] = closedPoly(open[i])
+		}
+		return simplified
+	}
+	if method == TopologyPreserving {
+		chains := make([]*chain, len(rings))
+		for i := range rings {
+			chains[i] = newChain(open[i], true)
+		}
+		for i, keep := range simplifyTopology(chains, tolerance) {
+			simplified[i] = closedPoly(keptPoints(open[i], keep))
+		}
+		return simplified
+	}
+	for i, ring := range open {
+		simplified[i] = NewPoly()
+		if keep := simplifyRing(ring, method, tolerance); keep != nil {
+			simplified[i] = closedPoly(keptPoints(ring, keep))
+		}
+	}
+	return simplified
+}
+
+// simplifyRing returns the points of a ring without closing point to keep, nil if the ring collapses
+func simplifyRing(ring []Point, method SimplifyMethod, tolerance float64) []bool {
+	n := len(ring)
+	if n < 4 {
+		keep := make([]bool, n)
+		for i := range keep {
+			keep[i] = true
+		}
+		return keep
+	}
+	if method == Visvalingam {
+		return visvalingam(ring, true, tolerance)
+	}
+	// the ring is split in 2 lines at the first point and the point furthest from it
+	far := 0
+	for i, p := range ring {
+		if math.Hypot(p.X-ring[0].X, p.Y-ring[0].Y) > math.Hypot(ring[far].X-ring[0].X, ring[far].Y-ring[0].Y) {
+			far = i
+		}
+	}
+	closed := append(append(make([]Point, 0, n+1), ring...), ring[0])
+	keep := make([]bool, n+1)
+	keep[0], keep[far] = true, true
+	douglasPeucker(closed, 0, far, tolerance, keep)
+	douglasPeucker(closed, far, n, tolerance, keep)
+	kept := 0
+	for _, k := range keep[:n] {
+		if k {
+			kept++
+		}
+	}
+	if kept < 3 {
+		return nil
+	}
+	return keep[:n]
+}
+
+// douglasPeucker keeps the points between first and last that are further than tolerance from the line between the
+// points that are kept (D.H. Douglas, T.K. Peucker)
+func douglasPeucker(points []Point, first, last int, tolerance float64, keep []bool) {
+	sections := [][2]int{{first, last}}
+	for len(sections) > 0 {
+		s := sections[len(sections)-1]
+		sections = sections[:len(sections)-1]
+		far, distance := furthest(points, s[0], s[1])
+		if far >= 0 && distance > tolerance {
+			keep[far] = true
+			sections = append(sections, [2]int{s[0], far}, [2]int{far, s[1]})
+		}
+	}
+}
+
+// furthest returns the point between first and last that is furthest from the segment first-last, -1 if there is none
+func furthest(points []Point, first, last int) (far int, distance float64) {
+	far = -1
+	for i := first + 1; i < last; i++ {
+		if d := segmentDistance(points[i], points[first], points[last]); far < 0 || d > distance {
+			far, distance = i, d
+		}
+	}
+	return
+}
+
+// segmentDistance returns the distance between p and the segment a-b
+func segmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := math.Max(0, math.Min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/(dx*dx+dy*dy)))
+	return math.Hypot(p.X-a.X-t*dx, p.Y-a.Y-t*dy)
+}
+
+// visvalingam removes the point with the smallest effective area, the area of the triangle with its neighbours,
+// until every area is at least tolerance² (M. Visvalingam, J.D. Whyatt). The area of a neighbour of a removed point is
+// never smaller than the area of the removed point, so points are removed in order of importance.
+// The end points of a line are kept, a ring keeps 3 points and collapses (nil) when their triangle is too small
+func visvalingam(points []Point, closed bool, tolerance float64) []bool {
+	n := len(points)
+	keep := make([]bool, n)
+	h := &areaHeap{area: make([]float64, n), at: make([]int, n)}
+	prev, next := make([]int, n), make([]int, n)
+	for i := range points {
+		keep[i], prev[i], next[i], h.at[i] = true, i-1, i+1, -1
+	}
+	if closed {
+		prev[0], next[n-1] = n-1, 0
+	}
+	area := func(i int) float64 { return math.Abs(cross(points[prev[i]], points[i], points[next[i]])) / 2 }
+	for i := range points {
+		if prev[i] >= 0 && next[i] < n {
+			h.area[i] = area(i)
+			heap.Push(h, i)
+		}
+	}
+	limit, left := tolerance*tolerance, n
+	for h.Len() > 0 && (!closed || left > 3) {
+		i := h.order[0]
+		if h.area[i] >= limit {
+			break
+		}
+		heap.Pop(h)
+		keep[i] = false
+		left--
+		p, q := prev[i], next[i]
+		next[p], prev[q] = q, p
+		for _, j := range []int{p, q} {
+			if h.at[j] >= 0 {
+				h.area[j] = math.Max(area(j), h.area[i])
+				heap.Fix(h, h.at[j])
+			}
+		}
+	}
+	if closed && left == 3 && h.Len() > 0 && h.area[h.order[0]] < limit {
+		return nil
+	}
+	return keep
+}
+
+// areaHeap orders point indexes by area, at is the position of a point in order or -1
+type areaHeap struct {
+	order []int
+	area  []float64
+	at    []int
+}
+
+func (h areaHeap) Len() int           { return len(h.order) }
+func (h areaHeap) Less(i, j int) bool { return h.area[h.order[i]] < h.area[h.order[j]] }
+func (h areaHeap) Swap(i, j int) {
+	h.order[i], h.order[j] = h.order[j], h.order[i]
+	h.at[h.order[i]], h.at[h.order[j]] = i, j
+}
+func (h *areaHeap) Push(x any) {
+	h.at[x.(int)] = len(h.order)
+	h.order = append(h.order, x.(int))
+}
+func (h *areaHeap) Pop() any {
+	i := h.order[len(h.order)-1]
+	h.order = h.order[:len(h.order)-1]
+	h.at[i] = -1
+	return i
+}
+
+// chain is a line or a ring (closed) that is simplified together with other chains, keep marks the points
+// that are left. Point len(p) of a ring is point 0
+type chain struct {
+	p       []Point
+	closed  bool
+	keep    []bool
+	segment []int // the segment of the segmentGrid that starts at every kept point
+}
+
+func newChain(points []Point, closed bool) *chain {
+	c := &chain{p: points, closed: closed, keep: make([]bool, len(points))}
+	for i := range c.keep {
+		c.keep[i] = true
+	}
+	return c
+}
+
+func (c *chain) at(i int) Point {
+	return c.p[i%len(c.p)]
+}
+
+// simplifyTopology is Douglas-Peucker on all chains together: a section of a chain is replaced by the segment between
+// its end points only when no other point is further than tolerance from the segment, the segment does not cross or
+// touch the other segments and no point of any chain is inside the area between the section and the segment.
+// Otherwise the section is split at its furthest point. A ring, or a line that ends where it starts, starts as a triangle
+// so it never collapses. Segments may meet at the points they have in common, like the end points of shared borders
+func simplifyTopology(chains []*chain, tolerance float64) [][]bool {
+	type section struct {
+		c           *chain
+		first, last int
+	}
+	var sections []section
+	for _, c := range chains {
+		n, end := len(c.p), len(c.p)
+		if !c.closed {
+			end = n - 1
+		}
+		switch {
+		case n < 3:
+		case !c.closed && !equalXY(c.p[0], c.p[end]):
+			sections = append(sections, section{c, 0, end})
+		case end < 4: // a triangle
+		default:
+			// the first point, the point furthest from it and the point furthest from the line between them
+			far := 0
+			for i, p := range c.p[:end] {
+				if math.Hypot(p.X-c.p[0].X, p.Y-c.p[0].Y) > math.Hypot(c.p[far].X-c.p[0].X, c.p[far].Y-c.p[0].Y) {
+					far = i
+				}
+			}
+			third, distance := -1, 0.0
+			for i, p := range c.p[:end] {
+				if d := segmentDistance(p, c.p[0], c.p[far]); i != 0 && i != far && (third < 0 || d > distance) {
+					third, distance = i, d
+				}
+			}
+			corners := []int{0, far, third, end}
+			if third < far {
+				corners = []int{0, third, far, end}
+			}
+			for i := 0; i < 3; i++ {
+				sections = append(sections, section{c, corners[i], corners[i+1]})
+			}
+		}
+	}
+	grid := newSegmentGrid(chains)
+	for len(sections) > 0 {
+		s := sections[len(sections)-1]
+		sections = sections[:len(sections)-1]
+		if s.last-s.first < 2 {
+			continue
+		}
+		far, distance := -1, 0.0
+		for i := s.first + 1; i < s.last; i++ {
+			if d := segmentDistance(s.c.at(i), s.c.at(s.first), s.c.at(s.last)); far < 0 || d > distance {
+				far, distance = i, d
+			}
+		}
+		if distance <= tolerance && flattenable(grid, s.c, s.first, s.last) {
+			for i := s.first + 1; i < s.last; i++ {
+				s.c.keep[i] = false
+			}
+			grid.flatten(s.c, s.first, s.last)
+			continue
+		}
+		sections = append(sections, section{s.c, far, s.last}, section{s.c, s.first, far})
+	}
+	keep := make([][]bool, len(chains))
+	for i, c := range chains {
+		keep[i] = c.keep
+	}
+	return keep
+}
+
+// flattenable reports if the points of chain c between first and last can be replaced by the segment between them
+// without a crossing or a point on the other side. Only the segments in the bounding box of the section are tested
+func flattenable(grid *segmentGrid, c *chain, first, last int) bool {
+	a, b := c.at(first), c.at(last)
+	area := make([]Point, 0, last-first+1)
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for i := first; i <= last; i++ {
+		p := c.at(i)
+		area = append(area, p)
+		minX, minY, maxX, maxY = math.Min(minX, p.X), math.Min(minY, p.Y), math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	outside := func(p, q Point) bool {
+		return math.Max(p.X, q.X) < minX || math.Min(p.X, q.X) > maxX || math.Max(p.Y, q.Y) < minY || math.Min(p.Y, q.Y) > maxY
+	}
+	return grid.search(minX, minY, maxX, maxY, func(s gridSegment) bool {
+		d, u, v := s.c, s.u, s.v
+		n := len(d.p)
+		for _, w := range []int{u, v} {
+			// skip the section itself and the points next to it, point len(p) of a ring is tested as point 0
+			in := d == c && (w%n == first%n || w%n == last%n || (w >= first && w <= last) || (c.closed && w == 0 && last == n))
+			if p := d.at(w); !in && w < n && !equalXY(p, a) && !equalXY(p, b) && !outside(p, p) && insideRing(p, area) {
+				return false
+			}
+		}
+		// skip the segments of the section and the segments next to it
+		if u != v && !(d == c && ((u >= first && v <= last) || v%n == first%n || u%n == last%n)) {
+			if p, q := d.at(u), d.at(v); !outside(p, q) && segmentsMeet(a, b, p, q) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// segmentGrid holds the segments between the kept points of chains in square cells, every segment is in the cells it
+// passes through. A cell is about the size of the average segment, so a section is only tested against the segments
+// near it. Segments that are replaced by flatten are dead and skipped
+type segmentGrid struct {
+	minX, minY, size float64
+	nx, ny           int
+	cells            [][]int
+	segments         []gridSegment
+	seen             []int // the search in which a segment was last found, so it is tested once
+	searches         int
+}
+
+// gridSegment is the segment from kept point u to kept point v of chain c, u == v for a chain of 1 point
+type gridSegment struct {
+	c    *chain
+	u, v int
+	dead bool
+}
+
+func newSegmentGrid(chains []*chain) *segmentGrid {
+	var points []Point
+	for _, c := range chains {
+		points = append(points, c.p...)
+	}
+	b := pointBounds(points)
+	w, h, n := b.MaxX-b.MinX, b.MaxY-b.MinY, float64(len(points)+1)
+	g := &segmentGrid{minX: b.MinX, minY: b.MinY, size: math.Sqrt(w * h / n)}
+	if g.size == 0 {
+		g.size = math.Max(w, h) / n
+	}
+	if g.size == 0 {
+		g.size = 1
+	}
+	g.nx, g.ny = int(w/g.size)+1, int(h/g.size)+1
+	g.cells = make([][]int, g.nx*g.ny)
+	for _, c := range chains {
+		c.segment = make([]int, len(c.p))
+		end := len(c.p) - 1
+		if c.closed {
+			end = len(c.p)
+		}
+		if len(c.p) == 1 {
+			g.add(c, 0, 0)
+		}
+		for v := 1; v <= end; v++ {
+			g.add(c, v-1, v)
+		}
+	}
+	return g
+}
+
+// add adds the segment from point u to point v of chain c to the cells along it. The cells are found at steps of
+// half a cell, a cell the segment only cuts at a corner may be missed, search looks one cell further
+func (g *segmentGrid) add(c *chain, u, v int) {
+	i := len(g.segments)
+	g.segments = append(g.segments, gridSegment{c: c, u: u, v: v})
+	g.seen = append(g.seen, 0)
+	c.segment[u] = i
+	p, q := c.at(u), c.at(v)
+	steps := int(2*math.Max(math.Abs(q.X-p.X), math.Abs(q.Y-p.Y))/g.size) + 1
+	for s := 0; s <= steps; s++ {
+		f := float64(s) / float64(steps)
+		x, y := g.cell(p.X+f*(q.X-p.X), p.Y+f*(q.Y-p.Y))
+		if cell := g.cells[y*g.nx+x]; len(cell) == 0 || cell[len(cell)-1] != i {
+			g.cells[y*g.nx+x] = append(cell, i)
+		}
+	}
+}
+
+// flatten replaces the segments of chain c between point first and point last by 1 segment
+func (g *segmentGrid) flatten(c *chain, first, last int) {
+	for u := first; u < last; u++ {
+		g.segments[c.segment[u]].dead = true
+	}
+	g.add(c, first, last)
+}
+
+// search calls test for every segment in the cells of the box and one cell around it, until test returns false
+func (g *segmentGrid) search(minX, minY, maxX, maxY float64, test func(s gridSegment) bool) bool {
+	g.searches++
+	x0, y0 := g.cell(minX, minY)
+	x1, y1 := g.cell(maxX, maxY)
+	for y := clampInt(y0-1, g.ny); y <= clampInt(y1+1, g.ny); y++ {
+		for x := clampInt(x0-1, g.nx); x <= clampInt(x1+1, g.nx); x++ {
+			for _, i := range g.cells[y*g.nx+x] {
+				if g.segments[i].dead || g.seen[i] == g.searches {
+					continue
+				}
+				g.seen[i] = g.searches
+				if !test(g.segments[i]) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// cell returns the column and row of the cell of x, y
+func (g *segmentGrid) cell(x, y float64) (int, int) {
+	return clampInt(int((x-g.minX)/g.size), g.nx), clampInt(int((y-g.minY)/g.size), g.ny)
+}
+
+// clampInt returns i limited to 0 up to n-1
+func clampInt(i, n int) int {
+	if i < 0 {
+		return 0
+	}
+	if i >= n {
+		return n - 1
+	}
+	return i
+}
+
+// segmentsMeet is segmentsTouch for segments that may have end points in common, segments with a common end point
+// meet when they are the same segment or when they overlap on a line
+func segmentsMeet(a, b, p, q Point) bool {
+	if !equalXY(a, p) && !equalXY(a, q) && !equalXY(b, p) && !equalXY(b, q) {
+		return segmentsTouch(a, b, p, q)
+	}
+	if (equalXY(a, p) && equalXY(b, q)) || (equalXY(a, q) && equalXY(b, p)) {
+		return true
+	}
+	between := func(s, t, x Point) bool {
+		return !equalXY(x, s) && !equalXY(x, t) && cross(s, t, x) == 0 && onSegment(s, t, x)
+	}
+	return between(a, b, p) || between(a, b, q) || between(p, q, a) || between(p, q, b)
+}
+
+// openRing returns a copy of the points of a ring without the closing point
+func openRing(points []Point) []Point {
+	ring := make([]Point, 0, len(points))
+	for _, p := range points {
+		p.UnDelete()
+		ring = append(ring, p)
+	}
+	if len(ring) > 1 && ring[0].X == ring[len(ring)-1].X && ring[0].Y == ring[len(ring)-1].Y {
+		ring = ring[:len(ring)-1]
+	}
+	return ring
+}
+
+func keptPoints(points []Point, keep []bool) (kept []Point) {
+	for i, p := range points {
+		if keep[i] {
+			kept = append(kept, p)
+		}
+	}
+	return
+}
@@ -0,0 +1,92 @@
+package Triangulate
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// gridShapes returns g × g unit squares whose borders have k points with a wiggle, neighbours share their borders
+func gridShapes(g, k int) [][]*Poly {
+	border := func(a, b Point, seed int64) []Point {
+		r := rand.New(rand.NewSource(seed))
+		points := []Point{a}
+		for i := 1; i < k; i++ {
+			f := float64(i) / float64(k)
+			w := 0.03*math.Sin(f*20) + 0.01*r.Float64()
+			dx, dy := b.X-a.X, b.Y-a.Y
+			points = append(points, Point{X: a.X + f*dx - w*dy, Y: a.Y + f*dy + w*dx})
+		}
+		return points
+	}
+	// backwards returns the border from its end to its start, without the start
+	backwards := func(points []Point, end Point) []Point {
+		out := []Point{end}
+		for i := len(points) - 1; i > 0; i-- {
+			out = append(out, points[i])
+		}
+		return out
+	}
+	corner := func(i, j int) Point { return Point{X: float64(i), Y: float64(j)} }
+	var shapes [][]*Poly
+	for i := 0; i < g; i++ {
+		for j := 0; j < g; j++ {
+			var ring []Point
+			ring = append(ring, border(corner(i, j), corner(i+1, j), int64(i*1000+j))...)
+			ring = append(ring, border(corner(i+1, j), corner(i+1, j+1), int64(1e6+(i+1)*1000+j))...)
+			ring = append(ring, backwards(border(corner(i, j+1), corner(i+1, j+1), int64(i*1000+j+1)), corner(i+1, j+1))...)
+			ring = append(ring, backwards(border(corner(i, j), corner(i, j+1), int64(1e6+i*1000+j)), corner(i, j+1))...)
+			shapes = append(shapes, []*Poly{closedPoly(ring)})
+		}
+	}
+	return shapes
+}
+
+func TestTopologySimplifyNoCrossings(t *testing.T) {
+	topology := NewTopology(gridShapes(12, 25))
+	arcs := len(topology.Arcs)
+	topology.Simplify(TopologyPreserving, 0.01)
+	if len(topology.Arcs) != arcs {
+		t.Fatalf("%d arcs, want %d", len(topology.Arcs), arcs)
+	}
+	var segments [][2]Point
+	kept := 0
+	for _, arc := range topology.Arcs {
+		kept += len(arc)
+		for i := 1; i < len(arc); i++ {
+			segments = append(segments, [2]Point{arc[i-1], arc[i]})
+		}
+	}
+	if kept == 0 || kept >= 12*12*4*25 {
+		t.Fatalf("%d points kept", kept)
+	}
+	for i, s := range segments {
+		for _, o := range segments[i+1:] {
+			if segmentsMeet(s[0], s[1], o[0], o[1]) {
+				t.Fatalf("segments %v and %v meet", s, o)
+			}
+		}
+	}
+}
+
+func TestSimplifyRingsKeepsIsland(t *testing.T) {
+	outer := closedPoly([]Point{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 5, Y: 10.5}, {X: 10, Y: 10}, {X: 10, Y: 0}})
+	island := closedPoly([]Point{{X: 5, Y: 10.3}, {X: 5.1, Y: 10.2}, {X: 4.9, Y: 10.2}})
+	if got := SimplifyRings([]*Poly{outer, island}, DouglasPeucker, 1)[0]; len(got.P) != 5 {
+		t.Fatalf("Douglas-Peucker kept %d points, the test needs the bump removed", len(got.P))
+	}
+	simplified := SimplifyRings([]*Poly{outer, island}, TopologyPreserving, 1)
+	ring := openRing(simplified[0].P)
+	for _, p := range openRing(simplified[1].P) {
+		if !insideRing(p, ring) {
+			t.Errorf("island point %v is outside %v", p, ring)
+		}
+	}
+}
+
+func BenchmarkTopologySimplify(b *testing.B) {
+	shapes := gridShapes(40, 25)
+	for i := 0; i < b.N; i++ {
+		NewTopology(shapes).Simplify(TopologyPreserving, 0.01)
+	}
+}
@@ -0,0 +1,177 @@
+package Triangulate
+
+// Topology stores the rings of many shapes as arcs, lines between junctions. A border that is shared by neighbouring
+// shapes is a single arc used by the rings of both shapes, so simplifying every arc once keeps the neighbours together
+// without gaps or overlaps. A junction is a point where a ring meets other neighbours than at the other places where
+// the point is used, a ring that shares no junction with other rings is a single arc that ends where it starts
+type Topology struct {
+	Arcs   [][]Point
+	Shapes [][][]int // the arcs of every ring of every shape in ring order, ^i is arc i in reverse order
+}
+
+// NewTopology finds the arcs of the rings of shapes, for example the parts of the records of a shapefile.
+// Rings may be closed or open, points are the same point when X and Y are equal
+func NewTopology(shapes [][]*Poly) *Topology {
+	t := &Topology{Shapes: make([][][]int, len(shapes))}
+	rings := make([][][]Point, len(shapes))
+	for s, shape := range shapes {
+		rings[s] = make([][]Point, len(shape))
+		for r, ring := range shape {
+			rings[s][r] = uniqueRing(ring.P)
+		}
+	}
+	junctions := findJunctions(rings)
+	// an arc is found by its first 2 points in both directions, 2 arcs that start with the same edge are the same arc
+	// because the arcs are cut at every point where rings part
+	arcs := make(map[[2][2]float64]int)
+	for s, shape := range rings {
+		t.Shapes[s] = make([][]int, len(shape))
+		for r, ring := range shape {
+			if len(ring) < 3 {
+				continue
+			}
+			for _, arc := range cutRing(ring, junctions) {
+				n := len(arc)
+				i, ok := arcs[[2][2]float64{xyKey(arc[0]), xyKey(arc[1])}]
+				if !ok {
+					i = len(t.Arcs)
+					t.Arcs = append(t.Arcs, arc)
+					arcs[[2][2]float64{xyKey(arc[0]), xyKey(arc[1])}] = i
+					arcs[[2][2]float64{xyKey(arc[n-1]), xyKey(arc[n-2])}] = ^i
+				}
+				t.Shapes[s][r] = append(t.Shapes[s][r], i)
+			}
+		}
+	}
+	return t
+}
+
+// Simplify simplifies every arc once, the end points of the arcs are kept so rings that share an arc stay together.
+// With TopologyPreserving all arcs are simplified together, no arc crosses another arc and a ring keeps at least 3
+// points. The tolerance is in map units like the tolerance of SimplifyLine
+func (t *Topology) Simplify(method SimplifyMethod, tolerance float64) {
+	if tolerance <= 0 {
+		return
+	}
+	if method != TopologyPreserving {
+		for i, arc := range t.Arcs {
+			t.Arcs[i] = SimplifyLine(arc, method, tolerance)
+		}
+		return
+	}
+	chains := make([]*chain, len(t.Arcs))
+	for i, arc := range t.Arcs {
+		chains[i] = newChain(arc, false)
+	}
+	for i, keep := range simplifyTopology(chains, tolerance) {
+		t.Arcs[i] = keptPoints(t.Arcs[i], keep)
+	}
+}
+
+// Rings returns the closed rings of a shape rebuilt from the arcs, a ring with less than 3 points left is empty
+func (t *Topology) Rings(shape int) []*Poly {
+	rings := make([]*Poly, len(t.Shapes[shape]))
+	for r, arcs := range t.Shapes[shape] {
+		var points []Point
+		for _, i := range arcs {
+			arc := t.arc(i)
+			if len(points) > 0 {
+				arc = arc[1:] // the first point is the last point of the previous arc
+			}
+			points = append(points, arc...)
+		}
+		rings[r] = NewPoly()
+		if ring := uniqueRing(points); len(ring) >= 3 {
+			rings[r] = closedPoly(ring)
+		}
+	}
+	return rings
+}
+
+// arc returns the points of arc i, a copy in reverse order for ^i
+func (t *Topology) arc(i int) []Point {
+	if i >= 0 {
+		return t.Arcs[i]
+	}
+	arc := t.Arcs[^i]
+	reversed := make([]Point, len(arc))
+	for j, p := range arc {
+		reversed[len(arc)-1-j] = p
+	}
+	return reversed
+}
+
+// findJunctions returns the points that have other neighbours in one ring than in another ring,
+// or than at another place in the same ring
+func findJunctions(shapes [][][]Point) map[[2]float64]bool {
+	neighbours := make(map[[2]float64][2][2]float64)
+	junctions := make(map[[2]float64]bool)
+	for _, shape := range shapes {
+		for _, ring := range shape {
+			n := len(ring)
+			if n < 3 {
+				continue
+			}
+			for i, p := range ring {
+				a, b := xyKey(ring[(i+n-1)%n]), xyKey(ring[(i+1)%n])
+				if b[0] < a[0] || (b[0] == a[0] && b[1] < a[1]) { // the direction of the ring does not matter
+					a, b = b, a
+				}
+				k := xyKey(p)
+				if seen, ok := neighbours[k]; !ok {
+					neighbours[k] = [2][2]float64{a, b}
+				} else if seen != [2][2]float64{a, b} {
+					junctions[k] = true
+				}
+			}
+		}
+	}
+	return junctions
+}
+
+// cutRing cuts a ring without closing point into arcs at its junctions. A ring without junctions is a single arc that
+// starts and ends at its lowest point, so every ring with the same points returns the same arc
+func cutRing(ring []Point, junctions map[[2]float64]bool) (arcs [][]Point) {
+	n, start := len(ring), -1
+	for i, p := range ring {
+		if junctions[xyKey(p)] {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+		for i, p := range ring {
+			if p.X < ring[start].X || (p.X == ring[start].X && p.Y < ring[start].Y) {
+				start = i
+			}
+		}
+	}
+	arc := []Point{ring[start]}
+	for i := 1; i <= n; i++ {
+		p := ring[(start+i)%n]
+		arc = append(arc, p)
+		if i == n || junctions[xyKey(p)] {
+			arcs = append(arcs, arc)
+			arc = []Point{p}
+		}
+	}
+	return
+}
+
+// uniqueRing returns a copy of the points of a ring without closing point and without consecutive duplicates
+func uniqueRing(points []Point) (ring []Point) {
+	for _, p := range openRing(points) {
+		if len(ring) == 0 || !equalXY(ring[len(ring)-1], p) {
+			ring = append(ring, p)
+		}
+	}
+	for len(ring) > 1 && equalXY(ring[0], ring[len(ring)-1]) {
+		ring = ring[:len(ring)-1]
+	}
+	return
+}
+
+func xyKey(p Point) [2]float64 {
+	return [2]float64{p.X, p.Y}
+}
@@ -0,0 +1,50 @@
+// TriPixel
+/* adapter between Triangulate and gopxl/pixel, Triangulate itself is pure Go
+and builds without cgo or GL headers
+*/
+package TriPixel
+
+import (
+	Tri "TriangMap/Triangulate"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// V converts a Triangulate vector to a pixel vector
+func V(v Tri.Vec) pixel.Vec {
+	return pixel.V(v.X, v.Y)
+}
+
+// Vec returns the X and Y of a point as pixel vector
+func Vec(p Tri.Point) pixel.Vec {
+	return pixel.V(p.X, p.Y)
+}
+
+// GetTriangles is Triangulate.GetTriangles with pixel vectors, every 3 vectors form a triangle
+func GetTriangles(poly *Tri.Poly, holes ...*Tri.Poly) ([]pixel.Vec, error) {
+	ears, err := Tri.GetTriangles(poly, holes...)
+	vecs := make([]pixel.Vec, len(ears))
+	for i, v := range ears {
+		vecs[i] = V(v)
+	}
+	return vecs, err
+}
+
+// MeshVecs returns the corners of the triangles of a mesh, every 3 vectors form a triangle
+func MeshVecs(m *Tri.Mesh) []pixel.Vec {
+	vecs := make([]pixel.Vec, len(m.Indices))
+	for i, v := range m.Indices {
+		vecs[i] = Vec(m.Vertices[v])
+	}
+	return vecs
+}
+
+// TrianglesData returns the triangles of a mesh for a pixel batch, pixel draws triangles without indices
+// so every corner is a vertex. The colours are left to the caller
+func TrianglesData(m *Tri.Mesh) *pixel.TrianglesData {
+	data := pixel.MakeTrianglesData(len(m.Indices))
+	for i, v := range m.Indices {
+		(*data)[i].Position = Vec(m.Vertices[v])
+	}
+	return data
+}
@@ -0,0 +1,48 @@
+package TriPixel
+
+import (
+	"math"
+	"testing"
+
+	Tri "TriangMap/Triangulate"
+)
+
+func TestMeshVecs(t *testing.T) {
+	m := Tri.NewMesh([]Tri.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 0}})
+	points := m.TrianglePoints()
+	vecs, data := MeshVecs(m), TrianglesData(m)
+	if len(vecs) != len(points) || len(*data) != len(points) {
+		t.Fatalf("got %d vectors and %d vertices, want %d", len(vecs), len(*data), len(points))
+	}
+	for i, p := range points {
+		if vecs[i].X != p.X || vecs[i].Y != p.Y {
+			t.Errorf("vector %d is %v, want %v", i, vecs[i], p)
+		}
+		if (*data)[i].Position != vecs[i] {
+			t.Errorf("vertex %d is at %v, want %v", i, (*data)[i].Position, vecs[i])
+		}
+	}
+}
+
+func TestGetTriangles(t *testing.T) {
+	poly := Tri.NewPoly()
+	for _, p := range []Tri.Point{{X: 0, Y: 0}, {X: 0, Y: 4}, {X: 4, Y: 4}, {X: 4, Y: 0}, {X: 0, Y: 0}} {
+		poly.Add(p)
+	}
+	hole := Tri.NewPoly()
+	for _, p := range []Tri.Point{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 3}, {X: 1, Y: 3}, {X: 1, Y: 1}} {
+		hole.Add(p)
+	}
+	vecs, err := GetTriangles(poly, hole)
+	if err != nil {
+		t.Fatal(err)
+	}
+	area := 0.0
+	for i := 0; i+2 < len(vecs); i += 3 {
+		a, b, c := vecs[i], vecs[i+1], vecs[i+2]
+		area += math.Abs((b.X-a.X)*(c.Y-a.Y)-(b.Y-a.Y)*(c.X-a.X)) / 2
+	}
+	if area != 12 {
+		t.Errorf("triangles cover %v, want 12", area)
+	}
+}
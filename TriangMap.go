@@ -8,6 +8,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"math"
 	"math/rand"
@@ -15,10 +16,13 @@ import (
 	Tri "TriangMap/Triangulate"
 	"regexp"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
+	Proj "TriangMap/Projection"
 	Shp "TriangMap/ShpReader"
+	TriPix "TriangMap/TriPixel"
 
 	"github.com/gopxl/pixel/v2"
 	"github.com/gopxl/pixel/v2/backends/opengl"
@@ -40,14 +44,16 @@ type Sizing struct {
 }
 
 var (
-	r            *rand.Rand
-	sizes        Sizing
-	cfg          opengl.WindowConfig
-	shapes       []Shp.ShapeData
-	head         Shp.Header
-	wg           sync.WaitGroup
-	imdReady     chan *imdraw.IMDraw
-	drawersReady chan []*pixel.Batch
+	r              *rand.Rand
+	sizes          Sizing
+	cfg            opengl.WindowConfig
+	shapes         []Shp.ShapeData
+	head           Shp.Header
+	options        Tri.Options
+	simplifyMethod Tri.SimplifyMethod
+	wg             sync.WaitGroup
+	imdReady       chan *imdraw.IMDraw
+	drawersReady   chan []*pixel.Batch
 )
 
 // var (
@@ -58,27 +64,89 @@ var (
 var (
 	src = flag.String("ShpFile", "world_.Shp", "Input shape file")
 	//src         = flag.String("ShpFile", "in.shp", "Input shape file")
-	trim        = flag.Int("TrimFactor", 0, "Trim factor: 0 does not remove coordinates, any other number trims points closer than % to previous point") ////*trim 0 = no simplification, 1200 is arbitrary value that seems to workd for complex models
+	tolerance   = flag.Float64("Tolerance", 0, "Simplification tolerance in map units: 0 does not remove coordinates, any other value removes points that add less detail than the tolerance")
+	simplify    = flag.String("Simplify", "douglaspeucker", "Simplification algorithm: douglaspeucker, visvalingam, or topology for Douglas-Peucker without crossing rings")
 	detailColor = flag.Bool("Detail", false, "True value shows triangle details in color variation per triangle")
+	colorField  = flag.String("ColorField", "", "Attribute (.dbf field) that groups entities, entities with the same value have the same color")
+	method      = flag.String("Method", "earclip", "Triangulation method: earclip, or delaunay for well shaped triangles (constrained Delaunay)")
+	repair      = flag.Bool("Repair", false, "Repair self-intersecting and invalid polygons before they are triangulated, the fixes are logged")
+	minAngle    = flag.Float64("MinAngle", 0, "Smallest angle of a triangle in degrees, up to about 30: adds points to the triangles (Delaunay refinement), 0 does not refine")
+	maxArea     = flag.Float64("MaxArea", 0, "Largest area of a triangle in screen pixels: adds points to the triangles (Delaunay refinement), 0 does not refine")
+	epsilon     = flag.Float64("Epsilon", 0, "Relative tolerance for points on a line, 0 keeps all points, e.g. 1e-12 leaves out points of the rings that are almost on a line with their neighbours")
+	projection  = flag.String("Projection", "none", "Map projection of lon/lat data: "+strings.Join(Proj.Names(), ", ")+", utm:<zone>[n|s] selects a UTM zone")
 )
 
 func translate(value float64, min float64, max float64, minrange float64, maxrange float64) float64 {
 	return minrange + (maxrange-minrange)*((value-min)/(max-min))
 }
 
+// elevationShade darkens a color relative to the Z range of the file
+func elevationShade(color pixel.RGBA, z float64) pixel.RGBA {
+	f := translate(z, head.MinZ, head.MaxZ, 0.3, 1)
+	return pixel.RGB(color.R*f, color.G*f, color.B*f)
+}
+
+// groupColor returns the same color base for the same attribute value
+func groupColor(value string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(value))
+	return float64(h.Sum32()%1000) / 1000
+}
+
 func main() {
 	flag.Parse()
-	fmt.Printf("processing file:%v Trimfactor:%d detailcolor:%t\n", *src, *trim, *detailColor)
+	fmt.Printf("processing file:%v tolerance:%v detailcolor:%t\n", *src, *tolerance, *detailColor)
 
 	runtime.GOMAXPROCS(runtime.NumCPU() * 2) //use double number of processes as queue length
-	bf, err := Shp.New(*src)
-	if err != nil {
-		log.Fatal(err)
+	switch *method {
+	case "earclip":
+	case "delaunay":
+		options.Method = Tri.ConstrainedDelaunay
+	default:
+		log.Fatalf("unknown triangulation method %s", *method)
 	}
-	head, shapes, err = Shp.ReadPolygons(&bf)
+	switch *simplify {
+	case "douglaspeucker":
+	case "visvalingam":
+		simplifyMethod = Tri.Visvalingam
+	case "topology":
+		simplifyMethod = Tri.TopologyPreserving
+	default:
+		log.Fatalf("unknown simplification algorithm %s", *simplify)
+	}
+	options.MinAngle, options.MaxArea = *minAngle, *maxArea // triangles are made after translation to the screen
+	options.Epsilon = *epsilon
+	dataset, err := Shp.Load(*src)
 	if err != nil {
 		log.Fatal(err)
 	}
+	head, shapes = dataset.Header, dataset.Shapes
+	for _, err := range dataset.Skipped { // a damaged file shows what could be read
+		log.Println("Skipped", err)
+	}
+	for _, err := range dataset.Warnings {
+		log.Println(err)
+	}
+	if dataset.Fields != nil {
+		fmt.Println("attributes:", dataset.Fields)
+	}
+	if dataset.CRS != nil {
+		fmt.Println("coordinate system:", dataset.CRS)
+	}
+	if dataset.CRS != nil && dataset.CRS.Projected {
+		if *projection != "none" {
+			log.Println("Data is already projected, projection", *projection, "ignored")
+		}
+	} else {
+		p, err := Proj.ByName(*projection, head.MinX, head.MinY, head.MaxX, head.MaxY)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if p != nil {
+			fmt.Println("projection:", p.Name())
+			Proj.Apply(p, shapes, &head) // before the coordinates are translated to the screen
+		}
+	}
 	Debug()
 
 	opengl.Run(run)
@@ -90,63 +158,126 @@ func createData() {
 	var pointCnt = 0
 	r := rand.New(rand.NewSource(time.Now().Unix()))
 	var lists [][]*Tri.Poly
+	var partTypes [][]int32 // part types of MultiPatch shapes, nil for other shapes
 	var drawers []*pixel.Batch
 	imd := imdraw.New(nil)
 	imd.Color = pixel.RGB(1, 1, 1) //border color
 	imd.EndShape = imdraw.RoundEndShape
-	for _, shape := range shapes {
+	borders := simplifyBorders()
+	for shapeNum, shape := range shapes {
 		var list []*Tri.Poly
-		for partNum := 0; partNum < int(shape.NumParts); partNum++ {
-			poly := Tri.NewPoly() //create new set
-			for _, points := range shape.Coordinates[partNum] {
+		switch shape.ShapeType {
+		case Shp.POINT, Shp.POINTZ, Shp.POINTM, Shp.MULTIPOINT, Shp.MULTIPOINTZ, Shp.MULTIPOINTM:
+			for _, points := range shape.Coordinates[0] {
 				x := translate(points[0], sizes.ValueMinX, sizes.ValueMaxX, sizes.ScreenMinX, sizes.ScreenMaxX)
 				y := translate(points[1], sizes.ValueMinY, sizes.ValueMaxY, sizes.ScreenMinY, sizes.ScreenMaxY)
-				poly.PushBack(Tri.Point{false, x, y}, float64(*trim)) //*trim 0 = no simplification, 1200 is arbitrary value that seems to workd for complex models
-				//Contours
 				imd.Push(pixel.V(x, y))
+				imd.Circle(1, 0)
+				pointCnt++
+			}
+			lists = append(lists, nil) // keep lists in line with shapes
+			partTypes = append(partTypes, nil)
+			continue
+		}
+		filled := shape.ShapeType == Shp.POLYGON || shape.ShapeType == Shp.POLYGONZ || shape.ShapeType == Shp.POLYGONM || shape.ShapeType == Shp.MULTIPATCH
+		parts := borders[shapeNum]
+		if parts == nil {
+			parts = shapeParts(shape)
+			if *tolerance > 0 && !filled { // polygons are simplified by simplifyBorders, MultiPatch is not simplified
+				for _, poly := range parts {
+					poly.P = Tri.SimplifyLine(poly.P, simplifyMethod, *tolerance)
+				}
+			}
+		}
+		for _, poly := range parts { // simplified in map units, then translated to the screen
+			for i, point := range poly.P {
+				poly.P[i].X = translate(point.X, sizes.ValueMinX, sizes.ValueMaxX, sizes.ScreenMinX, sizes.ScreenMaxX)
+				poly.P[i].Y = translate(point.Y, sizes.ValueMinY, sizes.ValueMaxY, sizes.ScreenMinY, sizes.ScreenMaxY)
+				//Contours
+				imd.Push(TriPix.Vec(poly.P[i]))
 				pointCnt++
 			}
 			//Contours
 			imd.Line(0.2)
-			list = append(list, poly)
+			if filled { // lines are only drawn as contours
+				list = append(list, poly)
+			}
 		}
 		lists = append(lists, list)
+		partTypes = append(partTypes, shape.PartTypes)
 	}
 	imdReady <- imd // prevents run loop from atempting to draw empty imd, which causes Panic
 	var jobs []chan int
 	var timespent []chan int64
-	for _, list := range lists {
+	for listNum, list := range lists {
 		job := make(chan int)
 		jobs = append(jobs, job)
 		timeC := make(chan int64)
 		timespent = append(timespent, timeC)
 		colorBase := r.Float64()
+		if *colorField != "" && shapes[listNum].Attributes != nil {
+			colorBase = groupColor(shapes[listNum].Attributes.String(*colorField))
+		}
 		color := pixel.RGB(colorBase, 0.3+colorBase, 0.5+colorBase)
-		go func(list []*Tri.Poly, job chan<- int, timeC chan int64) {
+		go func(list []*Tri.Poly, partTypes []int32, job chan<- int, timeC chan int64) {
 			triangleCnt := 0
 			mu.Lock()
 			now := time.Now()
 			mu.Unlock()
 			pointCnt := 0
-			for _, poly := range list {
-				//Triangels
-				triangles, err := Tri.GetTriangles(poly) // get all triangles to cover polygone area
+			var results [][]Tri.Point
+			if partTypes != nil { // MultiPatch, all parts together
+				parts := make([][]Tri.Point, len(list))
+				for i, poly := range list {
+					parts[i] = poly.P
+					pointCnt += len(poly.P)
+				}
+				triangles, err := Tri.GetPatchTriangles(parts, partTypes)
 				if err != nil {
-					log.Println("Triangulation error", err) // non fatal error, just might show gap in polygon
+					log.Println("Triangulation error", err)
+				}
+				results = append(results, triangles)
+			} else {
+				for _, poly := range list {
+					pointCnt += len(poly.P)
+				}
+				polygons := Tri.RingsToPolygons(list)
+				if *repair {
+					var repaired []Tri.Polygon
+					for _, polygon := range polygons {
+						valid, problems, err := Tri.Repair(polygon)
+						if err != nil {
+							log.Println("Repair error", err)
+						}
+						if len(problems) > 0 {
+							log.Println("Repaired", problems)
+						}
+						repaired = append(repaired, valid...)
+					}
+					polygons = repaired
 				}
-				pointCnt += len(poly.P)
-				trianglesdata := *pixel.MakeTrianglesData(len(triangles))
-				triangleCnt += len(triangles)
+				for _, polygon := range polygons { // holes (lakes, enclaves) stay empty
+					//Triangels
+					triangles, err := Tri.TriangulatePolygon(polygon, options) // get all triangles to cover polygone area
+					if err != nil {
+						log.Println("Triangulation error", err) // non fatal error, just might show gap in polygon
+					}
+					results = append(results, triangles)
+				}
+			}
+			for _, triangles := range results {
+				mesh := Tri.NewMesh(triangles)
+				trianglesdata := *TriPix.TrianglesData(mesh)
+				triangleCnt += len(trianglesdata)
 				r, g, b := 0.0, 0.0, 0.0 //r.Float64(), r.Float64(), r.Float64()
 				min := math.Min(math.Min(r, g), b)
 				r -= min
 				g -= min
 				b -= min
 				max := math.Max(math.Max(r, g), b)
-				inc := (1 - max) / float64(len(triangles))
+				inc := (1 - max) / float64(len(trianglesdata))
 				//fmt.Println(len(triangles), inc, r, g, b)
-				for i, _ := range triangles {
-					trianglesdata[i].Position = triangles[i]
+				for i := range trianglesdata {
 					if *detailColor {
 						mu.Lock() // to frequent call to rand makes the system crash, rand is not thread safe, causes Panic
 						color = pixel.RGB(r, g, b)
@@ -156,6 +287,9 @@ func createData() {
 						mu.Unlock()
 					}
 					trianglesdata[i].Color = color
+					if head.MaxZ > head.MinZ { // darker is lower
+						trianglesdata[i].Color = elevationShade(color, mesh.Vertices[mesh.Indices[i]].Z)
+					}
 				}
 				drawer := pixel.NewBatch(&trianglesdata, nil)
 				mu.Lock()
@@ -168,7 +302,7 @@ func createData() {
 			//fmt.Printf("This item:%d points, %d Triangles in %2.2f ms\n", pointCnt, triangleCnt, float64(duration.Nanoseconds())/1000000)
 			job <- triangleCnt
 			timeC <- duration.Nanoseconds()
-		}(list, job, timeC)
+		}(list, partTypes[listNum], job, timeC)
 	}
 	drawersReady <- drawers
 	var totalTimeSpent int64
@@ -182,6 +316,51 @@ func createData() {
 	fmt.Printf("Processed \n%d entities\n%d points\n%d triangles\n in %d ms\n", entityCnt, pointCnt, totalNumTriangles, totalTimeSpent/1000000)
 }
 
+// shapeParts returns the parts of a shape as polys in map units
+func shapeParts(shape Shp.ShapeData) []*Tri.Poly {
+	parts := make([]*Tri.Poly, shape.NumParts)
+	for partNum := range parts {
+		parts[partNum] = Tri.NewPoly() //create new set
+		for i, points := range shape.Coordinates[partNum] {
+			point := Tri.Point{X: points[0], Y: points[1]}
+			if shape.HasZ() {
+				point.Z = shape.Z[partNum][i]
+			}
+			if shape.HasM() {
+				point.M = shape.M[partNum][i]
+			}
+			parts[partNum].Add(point)
+		}
+	}
+	return parts
+}
+
+// simplifyBorders simplifies the rings of all polygon shapes together, a border shared by neighbouring shapes is
+// simplified once so the neighbours keep a common border. It returns the rings of every polygon shape, nil for other
+// shapes and nil for all shapes without tolerance. MultiPatch shapes are not simplified, removing points would break
+// triangle strips and fans
+func simplifyBorders() [][]*Tri.Poly {
+	borders := make([][]*Tri.Poly, len(shapes))
+	if *tolerance <= 0 {
+		return borders
+	}
+	var rings [][]*Tri.Poly
+	var shapeNums []int
+	for shapeNum, shape := range shapes {
+		switch shape.ShapeType {
+		case Shp.POLYGON, Shp.POLYGONZ, Shp.POLYGONM:
+			rings = append(rings, shapeParts(shape))
+			shapeNums = append(shapeNums, shapeNum)
+		}
+	}
+	topology := Tri.NewTopology(rings)
+	topology.Simplify(simplifyMethod, *tolerance)
+	for i, shapeNum := range shapeNums {
+		borders[shapeNum] = topology.Rings(i)
+	}
+	return borders
+}
+
 func run() {
 	var (
 		camPos       = pixel.ZV
@@ -2,15 +2,12 @@
 package Triangulate
 
 import (
-	"errors"
 	"fmt"
 	"math"
 	"regexp"
 	"runtime"
 	"time"
-
-	"github.com/gopxl/pixel/v2"
-	
+)
 
 func TimeTrack(start time.Time) {
 	// Skip this function, and fetch the PC and file for its parent
@@ -23,14 +20,28 @@ func TimeTrack(start time.Time) {
 	fmt.Printf("%s took %s\n", name, time.Since(start))
 }
 
+// Point is a vertex of a polygon, Z (elevation) and M (measure) are carried along
+// but are not used to calculate the triangles
 type Point struct {
 	A bool
 	X float64
 	Y float64
+	Z float64
+	M float64
 }
 
 // ZV is a zero Point.
-var ZP = Point{false, 0, 0}
+var ZP = Point{}
+
+// Vec is a vector in the X,Y plane. Triangulate has no graphics dependencies, TriPixel converts to pixel vectors
+type Vec struct {
+	X, Y float64
+}
+
+// V returns a new Vec
+func V(x, y float64) Vec {
+	return Vec{X: x, Y: y}
+}
 
 func (p *Point) Delete() {
 	p.A = true
@@ -40,8 +51,8 @@ func (p *Point) UnDelete() {
 	p.A = false
 }
 
-func (p *Point) Vec() pixel.Vec {
-	return pixel.V(p.X, p.Y)
+func (p *Point) Vec() Vec {
+	return V(p.X, p.Y)
 }
 
 func (p *Point) IsDeleted() bool {
@@ -49,8 +60,10 @@ func (p *Point) IsDeleted() bool {
 }
 
 func (p Point) String() string {
-	return fmt.Sprintf("Deleted: %t, X:%v, Y:%v", p.IsDeleted(), p.X, p.Y)
+	return fmt.Sprintf("Deleted: %t, X:%v, Y:%v, Z:%v, M:%v", p.IsDeleted(), p.X, p.Y, p.Z, p.M)
 }
+
+// Add and Sub work in the X,Y plane only
 func (p Point) Add(pt Point) Point {
 	p.X += pt.X
 	p.Y += pt.Y
@@ -62,23 +75,19 @@ func (p Point) Sub(pt Point) Point {
 	return p
 }
 
+// isConvex reports if p1, p2, p3 turn clockwise, a convex corner of a clockwise ring
 func isConvex(p1, p2, p3 Point) bool {
-	//http://myitlearnings.com/checking-collinearity-of-3-points-and-their-orientation/
-	return !isColinear(p1, p2, p3) && (p2.Y-p1.Y)*(p3.X-p2.X)-(p3.Y-p2.Y)*(p2.X-p1.X) >= 0
+	return Orient2D(p1, p2, p3) < 0
 }
 
 func isColinear(p1, p2, p3 Point) bool {
-	return p1.X*(p2.Y-p3.Y)+p2.X*(p3.Y-p1.Y)+p3.X*(p1.Y-p2.Y) == 0
-
+	return Orient2D(p1, p2, p3) == 0
 }
 
+// InTriangle reports if p is strictly inside triangle p1, p2, p3, a triangle without area contains no points
 func InTriangle(p1, p2, p3, p Point) bool {
-	//barycentric coordinates
-	α := ((p2.Y-p3.Y)*(p.X-p3.X) + (p3.X-p2.X)*(p.Y-p3.Y)) / ((p2.Y-p3.Y)*(p1.X-p3.X) + (p3.X-p2.X)*(p1.Y-p3.Y))
-	β := ((p3.Y-p1.Y)*(p.X-p3.X) + (p1.X-p3.X)*(p.Y-p3.Y)) / ((p2.Y-p3.Y)*(p1.X-p3.X) + (p3.X-p2.X)*(p1.Y-p3.Y))
-	γ := 1.0 - α - β
-	//fmt.Println(α, β, γ, α > 0 && β > 0 && γ > 0)
-	return α > 0 && β > 0 && γ > 0
+	d1, d2, d3 := sign(Orient2D(p1, p2, p)), sign(Orient2D(p2, p3, p)), sign(Orient2D(p3, p1, p))
+	return d1 != 0 && d1 == d2 && d2 == d3
 }
 
 type Poly struct {
@@ -97,16 +106,7 @@ func NewPoly() *Poly {
 // IsClockwise checks if points are organized clockwise,
 // Triangulation function works on clockwise ordered data only
 func (poly Poly) IsClockwise() bool {
-	p := poly.P
-	first := p[0]
-	last := p[len(p)-1]
-	sum := (first.X - last.X) * (first.Y + last.Y)
-	current := first
-	for _, e := range p {
-		sum += (e.X - current.X) * (e.Y + current.Y)
-		current = e
-	}
-	return sum > 0
+	return poly.SignedArea() < 0
 }
 
 // First() returns first not-deleted element and position, sets pointer to first valid element
@@ -203,6 +203,9 @@ func (poly *Poly) SetToLeftMost() {
 // PushBack Pushes a new point onto the last position of the poly and sets the point as not-deleted
 // if a limit is provided then points within this limit from the previouspoint will not be added
 // simplifying the area to triangulate. passing 0 does not not remove points. Compares the Δ of X and Y distances
+//
+// Deprecated: the limit is relative to the coordinate values and can make rings cross themselves,
+// use SimplifyRings or SimplifyLine with a tolerance in map units, or a Topology for shapes with shared borders
 func (poly *Poly) PushBack(p Point, limit float64) {
 	if limit > 0 && len(poly.P) > 0 {
 		p1 := poly.P[len(poly.P)-1]
@@ -230,14 +233,28 @@ func (poly *Poly) SetClockwise() {
 	}
 }
 
+// Centroid returns the centre of mass of the area of the ring, it does not depend on how densely the edges are
+// digitized. A ring without area returns the middle of its edges weighted by their length, a single point itself
 func (poly Poly) Centroid() Point {
+	ring := openRing(poly.P)
+	if len(ring) == 0 {
+		return ZP
+	}
+	a, x, y := ringMoments(ring, ring[0])
+	if a != 0 {
+		return Point{X: ring[0].X + x/(3*a), Y: ring[0].Y + y/(3*a)}
+	}
+	length := ringLength(ring)
+	if length == 0 {
+		return Point{X: ring[0].X, Y: ring[0].Y}
+	}
 	centroid := ZP
-	for _, pt := range poly.P {
-		centroid = centroid.Add(pt)
+	for i, p := range ring {
+		q := ring[(i+1)%len(ring)]
+		l := math.Hypot(q.X-p.X, q.Y-p.Y)
+		centroid.X += (p.X + q.X) / 2 * l / length
+		centroid.Y += (p.Y + q.Y) / 2 * l / length
 	}
-	centroid.X = centroid.X / float64(len(poly.P))
-	centroid.Y = centroid.Y / float64(len(poly.P))
-	//fmt.Println("Center:", poly.P, centroid)
 	return centroid
 }
 
@@ -247,95 +264,38 @@ func (poly *Poly) Add(p Point) {
 	poly.P = append(poly.P, p)
 }
 
-// GetTriangles calculates the triangles to cover the area of a polygon based on points of the polygon
-// Points should be ordered clockwise for this to work
-// In some rare cases the solution is not deterministic, the error shows the missing points
-func GetTriangles(poly *Poly) (ears []pixel.Vec, err error) {
-	poly.SetClockwise()
-	poly.SetToLeftMost()
-	loop := 0
-	for poly.Size() > 0 && loop < len(poly.P)*3 { //run until all elements are deleted & prevent endless loop
-		loop++
-		switch poly.Size() { // non deleted elements count
-		case 0, 1, 2: //        // can not be a triangle : should never happen
-			break
-		case 3: // 3 remaining points
-			{
-				var points = [3]Point{}
-				i := 0
-				for j, e := range poly.P {
-					if !e.IsDeleted() { // find not deleted points
-						points[i] = e  //save points
-						poly.Delete(j) // delete point
-						i++
-					}
-				}
-				if isConvex(points[0], points[1], points[2]) { // angle must be convex e.i. in polygon
-					ears = append(ears, points[0].Vec(), points[1].Vec(), points[2].Vec())
-
-				}
-				break
-			}
-		case 4:
-			{ // 4 remaining points, cut into 2 triangles
-				var points = [4]Point{}
-				i := 0
-				for j, e := range poly.P {
-					if !e.IsDeleted() { // find not deleted points
-						points[i] = e  //save points
-						poly.Delete(j) // delete point
-						i++
-					}
-				}
-				if isConvex(points[0], points[1], points[2]) { // angle must be convex e.i. in polygon
-					ears = append(ears, points[0].Vec(), points[1].Vec(), points[2].Vec())
-				}
-				if isConvex(points[0], points[2], points[3]) { // angle must be convex e.i. in polygon
-					ears = append(ears, points[0].Vec(), points[3].Vec(), points[2].Vec())
-				}
-				break
-			}
-		default:
-			for i := 1; i < poly.Size()-1; i++ {
-				p1, _ := poly.First() //retrieve 1st point for test
-				p2, i2 := poly.Next() //retrieve 2nd
-				p3, _ := poly.Next()  //retrieve 3rd
-				valid := true
-				if isConvex(p1, p2, p3) { // angle must be convex e.i. in polygon
-					for _, p := range poly.P { // test if any point (deleted or not) is inside the new triangle
-						if p != p1 && p != p2 && p != p3 { // only test for all other points, not self
-							if InTriangle(p1, p2, p3, p) {
-								poly.MoveToBack() //move first point to the end
-								valid = false
-								break
-							}
-						}
-					}
-					if valid { //cut ear
-						poly.Delete(i2)             //logically delete middle point
-						poly.MoveToBack()           //move firstpoint to end
-						if isColinear(p1, p2, p3) { //test for colinearity
-							//fmt.Println("3 in a row, not a triangle")
-						} else {
-							ears = append(ears, p1.Vec(), p2.Vec(), p3.Vec())
-						}
-					}
-				} else {
-					//not convex p1, p2, p3
-					poly.MoveToBack() //move first point to the end
-				}
-			}
-		}
+// GetTriangles calculates the triangles to cover the area of a polygon based on points of the polygon.
+// The areas of holes are left empty, see GetPolygonTriangles
+//
+// Deprecated: every corner is a copy of a polygon point, use TriangulateMesh for shared vertices
+func GetTriangles(poly *Poly, holes ...*Poly) (ears []Vec, err error) {
+	var points []Point
+	if len(holes) > 0 {
+		points, err = GetPolygonTriangles(Polygon{Outer: poly, Holes: holes})
+	} else {
+		points, err = GetTrianglePoints(poly)
 	}
-	if loop >= len(poly.P)*3 {
-		pointStr := ""
-		for i, point := range poly.P {
-			if !point.IsDeleted() {
-				pointStr += fmt.Sprintf("%d, %v\n", i, point)
-			}
-		}
-		err = errors.New(fmt.Sprintf(" not deterministic:  %d iterations for %d points %d points remaining %v\n", loop, len(poly.P), poly.Size(), pointStr))
-		//fmt.Println(poly)
+	ears = make([]Vec, len(points))
+	for i := range points {
+		ears[i] = points[i].Vec()
 	}
 	return ears, err
 }
+
+// GetTrianglePoints calculates the same triangles as GetTriangles but returns the polygon points,
+// every 3 points form a triangle. The Z and M values of the points are preserved so
+// the result can be used as an elevation aware mesh.
+// The ring may be clockwise or counter-clockwise, closed or open, poly is not changed. A simple ring of n points
+// always gives n-2 triangles, duplicate points and points on a line between their neighbours are skipped
+// when they can not be the corner of a triangle. The error reports a ring that is not simple, its triangles may overlap
+func GetTrianglePoints(poly *Poly) (ears []Point, err error) {
+	c, start, size := newEarClipper(orientedRing(poly.P, true))
+	if size < 3 {
+		return nil, nil
+	}
+	c.clip(start, size)
+	if c.forced > 0 {
+		err = fmt.Errorf("ring of %d points is not simple, %d triangles may overlap", size, c.forced)
+	}
+	return c.ears, err
+}
@@ -0,0 +1,224 @@
+package Triangulate
+
+import (
+	"math"
+	"sort"
+)
+
+// Triangulation is the Delaunay triangulation of a point set, e.g. a TIN of measuring stations
+type Triangulation struct {
+	Points    []Point  // the points without duplicates
+	Index     []int    // index in Points of every input point
+	Triangles [][3]int // indexes in Points, counter-clockwise
+	Neighbors [][3]int // triangle across edge i, from Triangles[t][i] to Triangles[t][(i+1)%3], -1 on the convex hull
+}
+
+// VoronoiCell is the area that is closer to point Site of the triangulation than to any other point. The polygons
+// have clockwise outer rings and counter-clockwise holes like a shapefile, a concave bounds can cut a cell in parts
+type VoronoiCell struct {
+	Site     int
+	Polygons []Polygon
+}
+
+// Delaunay returns the Delaunay triangulation of points, duplicate points are used once.
+// The points are inserted one by one, the edges of the convex hull are then forced into the triangulation so
+// the triangles cover the convex hull completely. Less than 3 points or points on a line give no triangles
+func Delaunay(points []Point) (tr *Triangulation, err error) {
+	tr = &Triangulation{Index: make([]int, len(points))}
+	mesh := newTriMesh(points)
+	for i, p := range points {
+		p.UnDelete()
+		tr.Index[i] = mesh.insert(p) - 3
+	}
+	tr.Points = make([]Point, len(mesh.points)-3)
+	copy(tr.Points, mesh.points[3:])
+	hull := convexHull(tr.Points)
+	if len(hull) < 3 {
+		return tr, nil
+	}
+	for i := range hull {
+		if e := mesh.insertConstraint(hull[i]+3, hull[(i+1)%len(hull)]+3); e != nil && err == nil {
+			err = e
+		}
+	}
+	mesh.legalizeAll()
+	inside := mesh.interior()
+	number := make(map[int]int, len(inside)) // triangle number in the mesh to number in the triangulation
+	for n, t := range inside {
+		number[t] = n
+	}
+	tr.Triangles = make([][3]int, len(inside))
+	tr.Neighbors = make([][3]int, len(inside))
+	for n, t := range inside {
+		for i := 0; i < 3; i++ {
+			tr.Triangles[n][i] = mesh.tris[t][i] - 3
+			tr.Neighbors[n][i] = -1
+			if u, ok := number[mesh.adj[t][i]]; ok {
+				tr.Neighbors[n][i] = u
+			}
+		}
+	}
+	return tr, err
+}
+
+// TrianglePoints returns the corners of the triangles, every 3 points form a triangle
+func (tr *Triangulation) TrianglePoints() []Point {
+	points := make([]Point, 0, 3*len(tr.Triangles))
+	for _, t := range tr.Triangles {
+		points = append(points, tr.Points[t[0]], tr.Points[t[1]], tr.Points[t[2]])
+	}
+	return points
+}
+
+// Neighbours returns the indexes of the points that share a triangle edge with every point
+func (tr *Triangulation) Neighbours() [][]int {
+	neighbours := make([][]int, len(tr.Points))
+	for _, t := range tr.Triangles {
+		for i := 0; i < 3; i++ {
+			a, b := t[i], t[(i+1)%3]
+			neighbours[a] = appendUnique(neighbours[a], b)
+			neighbours[b] = appendUnique(neighbours[b], a)
+		}
+	}
+	return neighbours
+}
+
+func appendUnique(list []int, n int) []int {
+	for _, e := range list {
+		if e == n {
+			return list
+		}
+	}
+	return append(list, n)
+}
+
+// Voronoi returns the Voronoi (Thiessen) cells of the points clipped to bounds, every cell is a convex bounds cut by
+// the perpendicular bisectors of the point and its Delaunay neighbours. Without bounds the box of the points with a
+// margin of 10% is used. A concave bounds, e.g. the outline of a country, is replaced by its bounding box and every
+// cell is intersected with it by Boolean, a cell may then fall apart in several polygons.
+// Cells that are completely outside bounds are left out
+func (tr *Triangulation) Voronoi(bounds []Point) (cells []VoronoiCell, err error) {
+	if len(tr.Points) == 0 {
+		return nil, nil
+	}
+	if len(bounds) < 3 {
+		bounds = boxAround(tr.Points, 0.1)
+	}
+	bounds = orientedRing(bounds, false)
+	start, concave := bounds, !convexRing(bounds)
+	if concave {
+		start = boxAround(bounds, 0)
+	}
+	neighbours := tr.Neighbours()
+	for site, p := range tr.Points {
+		others := neighbours[site]
+		if len(tr.Triangles) == 0 { // points on a line, all points are neighbours
+			others = nil
+			for i := range tr.Points {
+				if i != site {
+					others = append(others, i)
+				}
+			}
+		}
+		cell := start
+		for _, other := range others {
+			q := tr.Points[other]
+			// points x closer to p than to q: (q-p).x <= (|q|²-|p|²)/2
+			cell = clipHalfPlane(cell, q.X-p.X, q.Y-p.Y, (q.X*q.X+q.Y*q.Y-p.X*p.X-p.Y*p.Y)/2)
+			if len(cell) < 3 {
+				break
+			}
+		}
+		if len(cell) < 3 {
+			continue
+		}
+		polygons := []Polygon{{Outer: closedPoly(orientedRing(cell, true))}}
+		if concave {
+			var e error
+			if polygons, e = Boolean(polygons, []Polygon{{Outer: closedPoly(bounds)}}, Intersection); e != nil && err == nil {
+				err = e
+			}
+		}
+		if len(polygons) > 0 {
+			cells = append(cells, VoronoiCell{Site: site, Polygons: polygons})
+		}
+	}
+	return
+}
+
+// convexRing reports if a counter-clockwise ring turns left or goes straight at every point
+func convexRing(ring []Point) bool {
+	n := len(ring)
+	for i, p := range ring {
+		if cross(ring[(i+n-1)%n], p, ring[(i+1)%n]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// clipHalfPlane returns the part of ring where a*x + b*y <= c (Sutherland-Hodgman)
+func clipHalfPlane(ring []Point, a, b, c float64) (clipped []Point) {
+	for i, s := range ring {
+		e := ring[(i+1)%len(ring)]
+		ds, de := a*s.X+b*s.Y-c, a*e.X+b*e.Y-c
+		if ds <= 0 {
+			clipped = append(clipped, s)
+		}
+		if (ds < 0 && de > 0) || (ds > 0 && de < 0) {
+			f := ds / (ds - de)
+			clipped = append(clipped, Point{X: s.X + f*(e.X-s.X), Y: s.Y + f*(e.Y-s.Y), Z: s.Z + f*(e.Z-s.Z), M: s.M + f*(e.M-s.M)})
+		}
+	}
+	return
+}
+
+// boxAround returns the bounding box of points enlarged by margin times its size as counter-clockwise ring
+func boxAround(points []Point, margin float64) []Point {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY, maxX, maxY = math.Min(minX, p.X), math.Min(minY, p.Y), math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	dx, dy := (maxX-minX)*margin, (maxY-minY)*margin
+	if dx == 0 && dy == 0 {
+		dx, dy = 1, 1
+	}
+	if dx == 0 {
+		dx = dy
+	}
+	if dy == 0 {
+		dy = dx
+	}
+	return []Point{{X: minX - dx, Y: minY - dy}, {X: maxX + dx, Y: minY - dy}, {X: maxX + dx, Y: maxY + dy}, {X: minX - dx, Y: maxY + dy}}
+}
+
+// convexHull returns the indexes of the corners of the convex hull counter-clockwise, points on the hull
+// between corners are left out (A.M. Andrew, monotone chain)
+func convexHull(points []Point) []int {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := points[order[i]], points[order[j]]
+		return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+	})
+	if len(order) < 3 {
+		return order
+	}
+	hull := make([]int, 0, 2*len(order))
+	for pass := 0; pass < 2; pass++ { // lower hull from left to right, upper hull from right to left
+		start := len(hull)
+		for _, i := range order {
+			for len(hull) >= start+2 && cross(points[hull[len(hull)-2]], points[hull[len(hull)-1]], points[i]) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, i)
+		}
+		hull = hull[:len(hull)-1] // the last point is the first of the other half
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+	return hull
+}
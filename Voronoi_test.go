@@ -0,0 +1,134 @@
+package Triangulate
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// checkDelaunay checks that the triangles are counter-clockwise, have no point inside their circle and cover the
+// convex hull of the points
+func checkDelaunay(t *testing.T, name string, points []Point) *Triangulation {
+	tr, err := Delaunay(points)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	for i, p := range points {
+		if q := tr.Points[tr.Index[i]]; !equalXY(p, q) {
+			t.Fatalf("%s: point %d is %v, want %v", name, i, q, p)
+		}
+	}
+	area := 0.0
+	for n, tri := range tr.Triangles {
+		a, b, c := tr.Points[tri[0]], tr.Points[tri[1]], tr.Points[tri[2]]
+		if Orient2D(a, b, c) <= 0 {
+			t.Fatalf("%s: triangle %v %v %v is not counter-clockwise", name, a, b, c)
+		}
+		for _, p := range tr.Points {
+			if InCircle(a, b, c, p) > 0 {
+				t.Fatalf("%s: %v is inside the circle of triangle %v %v %v", name, p, a, b, c)
+			}
+		}
+		for i, u := range tr.Neighbors[n] {
+			if u >= 0 && tr.Neighbors[u][0] != n && tr.Neighbors[u][1] != n && tr.Neighbors[u][2] != n {
+				t.Fatalf("%s: triangle %d is a neighbour of %d across edge %d, but not the other way", name, u, n, i)
+			}
+		}
+		area += cross(a, b, c) / 2
+	}
+	var hull []Point
+	for _, i := range convexHull(tr.Points) {
+		hull = append(hull, tr.Points[i])
+	}
+	if want := signedArea(hull); math.Abs(area-want) > 1e-9*want {
+		t.Fatalf("%s: triangles cover %v, want the hull area %v", name, area, want)
+	}
+	return tr
+}
+
+func TestDelaunay(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	for k := 0; k < 20; k++ {
+		var points []Point
+		for i := 0; i < 100; i++ {
+			points = append(points, Point{X: 100 * r.Float64(), Y: 50 * r.Float64()})
+		}
+		points = append(points, points[3], points[7]) // duplicates
+		checkDelaunay(t, "random", points)
+	}
+	// 4 points on every circle of a lattice cell
+	var lattice []Point
+	for i := 0; i < 12; i++ {
+		for j := 0; j < 9; j++ {
+			lattice = append(lattice, Point{X: float64(i), Y: float64(j)})
+		}
+	}
+	if tr := checkDelaunay(t, "lattice", lattice); len(tr.Triangles) != 2*11*8 {
+		t.Errorf("lattice: %d triangles, want %d", len(tr.Triangles), 2*11*8)
+	}
+}
+
+// checkVoronoi checks that the cells cover bounds without overlap: the area of the cells is the area of bounds and
+// random points inside bounds are in the cell of the nearest point only
+func checkVoronoi(t *testing.T, name string, r *rand.Rand, tr *Triangulation, bounds []Point) []VoronoiCell {
+	cells, err := tr.Voronoi(bounds)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	area := 0.0
+	for _, cell := range cells {
+		for _, polygon := range cell.Polygons {
+			if !polygon.Outer.IsClockwise() {
+				t.Fatalf("%s: cell %d has a counter-clockwise outer ring", name, cell.Site)
+			}
+		}
+		area += polygonsArea(cell.Polygons)
+	}
+	if want := math.Abs(signedArea(bounds)); math.Abs(area-want) > 1e-9*want {
+		t.Fatalf("%s: cells cover %v, want %v", name, area, want)
+	}
+	box := boxAround(bounds, 0)
+	for s := 0; s < 500; s++ {
+		p := Point{X: box[0].X + (box[2].X-box[0].X)*r.Float64(), Y: box[0].Y + (box[2].Y-box[0].Y)*r.Float64()}
+		nearest := 0
+		for i, q := range tr.Points {
+			if math.Hypot(p.X-q.X, p.Y-q.Y) < math.Hypot(p.X-tr.Points[nearest].X, p.Y-tr.Points[nearest].Y) {
+				nearest = i
+			}
+		}
+		in := insideRing(p, bounds)
+		for _, cell := range cells {
+			if got, want := inPolygons(cell.Polygons, p), in && cell.Site == nearest; got != want {
+				t.Fatalf("%s: %v in the cell of %d is %v, want %v", name, p, cell.Site, got, want)
+			}
+		}
+	}
+	return cells
+}
+
+func TestVoronoi(t *testing.T) {
+	r := rand.New(rand.NewSource(14))
+	var points []Point
+	for i := 0; i < 50; i++ {
+		points = append(points, Point{X: 10 * r.Float64(), Y: 10 * r.Float64()})
+	}
+	tr, err := Delaunay(points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkVoronoi(t, "box", r, tr, []Point{{X: -1, Y: -1}, {X: 11, Y: -1}, {X: 11, Y: 11}, {X: -1, Y: 11}})
+	checkVoronoi(t, "default", r, tr, boxAround(tr.Points, 0.1))
+	checkVoronoi(t, "concave", r, tr, []Point{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 3, Y: 10}, {X: 3, Y: 3}, {X: 7, Y: 3},
+		{X: 7, Y: 10}, {X: 10, Y: 10}, {X: 10, Y: 0}})
+
+	// the cell of the upper point is cut in the 2 arms of a U
+	u := []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 7, Y: 10}, {X: 7, Y: 3}, {X: 3, Y: 3}, {X: 3, Y: 10}, {X: 0, Y: 10}}
+	tr, err = Delaunay([]Point{{X: 5, Y: 1}, {X: 5, Y: 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := checkVoronoi(t, "U", r, tr, u)
+	if len(cells) != 2 || len(cells[1].Polygons) != 2 || polygonsArea(cells[1].Polygons) != 30 {
+		t.Fatalf("U: got %d cells, want the lower point with 1 polygon and the upper point with 2 polygons of area 30", len(cells))
+	}
+}